@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/metadata"
+	"k8s.io/klog/v2"
+
+	"github.com/EduardoVega/kubegraph/graph"
+)
+
+// DiffOptions defines the diff command options
+type DiffOptions struct {
+	*Options
+	Contexts          []string
+	CompareNamespaces []string
+	Snapshots         []*graph.Snapshot
+}
+
+// NewDiffCmd returns a new command that compares two graphs
+func NewDiffCmd(o *Options) *cobra.Command {
+	d := &DiffOptions{
+		Options: o,
+	}
+
+	c := &cobra.Command{
+		Use:   "diff BEFORE_SNAPSHOT AFTER_SNAPSHOT | [KIND[,KIND...]] [NAME...] | [KIND/NAME...] (--contexts BEFORE,AFTER | --namespaces BEFORE,AFTER) [flags]",
+		Short: "Print the added, removed and changed objects and relations between two graphs",
+		Example: `
+# Print the differences between two snapshots saved with --save
+kubegraph diff before.json after.json
+
+# Print the differences between the graphs of the deployment api in the staging and prod namespaces
+kubegraph diff deploy api --namespaces staging,prod
+
+# Print a colored DOT graph of the differences between the graphs of the service web in two clusters
+kubegraph diff svc web --contexts staging,prod -o dot
+`,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := d.Complete(c, args); err != nil {
+				return err
+			}
+			if err := d.Validate(); err != nil {
+				return err
+			}
+			if err := d.Run(); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	c.Flags().StringSliceVar(&d.Contexts, "contexts", d.Contexts, "Comma separated pair of kubeconfig contexts whose graphs are compared")
+	c.Flags().StringSliceVar(&d.CompareNamespaces, "namespaces", d.CompareNamespaces, "Comma separated pair of namespaces whose graphs are compared, the objects are matched by kind and name")
+
+	return c
+}
+
+// Complete loads the snapshots, or reads the roots and namespace of the graphs to compare
+func (d *DiffOptions) Complete(cmd *cobra.Command, args []string) error {
+	klog.V(1).Infoln("add information to DiffOptions struct")
+
+	if d.DotGraph {
+		d.Output = graph.OutputDot
+	}
+
+	if len(d.Contexts) == 0 && len(d.CompareNamespaces) == 0 {
+		if len(args) != 2 {
+			return fmt.Errorf("two snapshot files are required, or --contexts or --namespaces to compare live graphs")
+		}
+
+		for _, a := range args {
+			s, err := graph.LoadSnapshot(a)
+			if err != nil {
+				return err
+			}
+
+			d.Snapshots = append(d.Snapshots, s)
+		}
+
+		return nil
+	}
+
+	if len(d.Contexts) != 0 && len(d.Contexts) != 2 {
+		return fmt.Errorf("--contexts requires two contexts, got %d", len(d.Contexts))
+	}
+
+	if len(d.CompareNamespaces) != 0 && len(d.CompareNamespaces) != 2 {
+		return fmt.Errorf("--namespaces requires two namespaces, got %d", len(d.CompareNamespaces))
+	}
+
+	roots, err := graph.NewRoots(args)
+	if err != nil {
+		return err
+	}
+
+	namespace, err := cmd.Flags().GetString("namespace")
+	if err != nil {
+		return err
+	}
+
+	d.Roots = roots
+	d.Namespaces = []string{namespace}
+
+	return nil
+}
+
+// getSnapshots builds the graphs of the roots in the contexts or namespaces to compare
+func (d *DiffOptions) getSnapshots() error {
+	for i := 0; i < 2; i++ {
+		context := ""
+		if len(d.Contexts) == 2 {
+			context = d.Contexts[i]
+		}
+
+		ns := d.Namespaces[0]
+		if len(d.CompareNamespaces) == 2 {
+			ns = d.CompareNamespaces[i]
+		}
+
+		s, err := d.getSnapshot(context, ns, d.Roots)
+		if err != nil {
+			return err
+		}
+
+		d.Snapshots = append(d.Snapshots, s)
+	}
+
+	return nil
+}
+
+// getSnapshot returns the snapshot of the graph of the roots in the context and namespace.
+// An empty context or namespace means the ones of the kubeconfig flags.
+func (d *DiffOptions) getSnapshot(context, namespace string, roots []graph.Root) (*graph.Snapshot, error) {
+	configFlags := copyConfigFlags(d.ConfigFlags)
+
+	if context != "" {
+		configFlags.Context = &context
+	}
+
+	if namespace == "" {
+		ns, _, err := configFlags.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return nil, err
+		}
+
+		namespace = ns
+	}
+
+	klog.V(1).Infof("build graph of context '%s' in namespace '%s'", context, namespace)
+
+	restConfig, err := configFlags.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dynClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	b := graph.NewBuilder(graph.NewDynamicSource(dynClient), io.Discard, graph.OutputTree, []string{namespace}, roots)
+	b.ChunkSize = d.ChunkSize
+
+	if d.MetadataOnly {
+		metadataClient, err := metadata.NewForConfig(restConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		b.MetadataSource = graph.NewMetadataSource(metadataClient)
+	}
+
+	err = b.Collect()
+	if err != nil {
+		return nil, err
+	}
+
+	return graph.NewSnapshot(b.ObjsData, b.Warnings), nil
+}
+
+// copyConfigFlags returns a copy of the kubeconfig flags, so the context can be changed
+// while the server, credentials and impersonation flags are kept. A new struct is returned
+// because the client config of the flags is cached once it is loaded.
+func copyConfigFlags(f *genericclioptions.ConfigFlags) *genericclioptions.ConfigFlags {
+	c := genericclioptions.NewConfigFlags(true)
+
+	c.CacheDir = f.CacheDir
+	c.KubeConfig = f.KubeConfig
+	c.ClusterName = f.ClusterName
+	c.AuthInfoName = f.AuthInfoName
+	c.Context = f.Context
+	c.Namespace = f.Namespace
+	c.APIServer = f.APIServer
+	c.TLSServerName = f.TLSServerName
+	c.Insecure = f.Insecure
+	c.CertFile = f.CertFile
+	c.KeyFile = f.KeyFile
+	c.CAFile = f.CAFile
+	c.BearerToken = f.BearerToken
+	c.Impersonate = f.Impersonate
+	c.ImpersonateGroup = f.ImpersonateGroup
+	c.Username = f.Username
+	c.Password = f.Password
+	c.Timeout = f.Timeout
+
+	return c
+}
+
+// Validate ensures all expected data is available
+func (d *DiffOptions) Validate() error {
+	klog.V(1).Infoln("validate DiffOptions struct")
+
+	switch d.Output {
+	case graph.OutputTree, graph.OutputDot, graph.OutputJSON:
+	default:
+		return fmt.Errorf("output format '%s' not supported, one of: tree|dot|json", d.Output)
+	}
+
+	if d.ChunkSize < 0 {
+		return fmt.Errorf("chunk-size must be 0 or greater")
+	}
+
+	return nil
+}
+
+// Run builds the graphs, if they were not loaded from snapshots, and prints the diff between them
+func (d *DiffOptions) Run() error {
+	klog.V(1).Infoln("print the diff between the graphs")
+
+	if len(d.Snapshots) == 0 {
+		err := d.getSnapshots()
+		if err != nil {
+			return err
+		}
+	}
+
+	diff := graph.NewDiff(d.Snapshots[0], d.Snapshots[1], len(d.CompareNamespaces) == 2)
+
+	switch d.Output {
+	case graph.OutputDot:
+		err := diff.PrintDot(d.Out)
+		if err != nil {
+			return err
+		}
+	case graph.OutputJSON:
+		err := diff.PrintJSON(d.Out)
+		if err != nil {
+			return err
+		}
+	default:
+		diff.PrintTree(d.Out)
+	}
+
+	if !diff.HasChanges() {
+		fmt.Fprintln(d.ErrOut, "No differences found")
+	}
+
+	warnings := 0
+
+	for _, s := range d.Snapshots {
+		for _, w := range s.Warnings {
+			fmt.Fprintf(d.ErrOut, "Warning: %s\n", w)
+			warnings++
+		}
+	}
+
+	if d.Strict && warnings > 0 {
+		return fmt.Errorf("the graphs are incomplete, %d warning(s) found", warnings)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewHelmReleaseCmd returns a new command that graphs the objects of a helm release
+func NewHelmReleaseCmd(o *Options) *cobra.Command {
+	c := &cobra.Command{
+		Use:   "helm-release RELEASE [flags]",
+		Short: "Print a tree or dot graph of the objects of a helm release and their related objects",
+		Example: `
+# Print a tree graph of the objects of the helm release my-app
+kubegraph helm-release my-app
+
+# Print a DOT graph of the objects of the helm release my-app in the namespace shop
+kubegraph helm-release my-app --namespace shop --dot
+`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := o.Complete(c, nil); err != nil {
+				return err
+			}
+
+			o.HelmRelease = args[0]
+
+			if err := o.Validate(nil); err != nil {
+				return err
+			}
+			if err := o.Run(); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	return c
+}
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/spf13/pflag"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
 	"fmt"
@@ -14,9 +20,11 @@ import (
 	"github.com/EduardoVega/kubegraph/internal"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 	"k8s.io/klog/v2"
 
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/metadata"
 	_ "k8s.io/client-go/plugin/pkg/client/auth" // support for cloud providers auth
 )
 
@@ -24,12 +32,28 @@ import (
 type Options struct {
 	ConfigFlags *genericclioptions.ConfigFlags
 	genericclioptions.IOStreams
-	Client       dynamic.Interface
-	Namespace    string
-	Name         string
-	Kind         string
-	DotGraph     bool
-	PrintVersion bool
+	Client         dynamic.Interface
+	Source         graph.Source
+	MetadataSource graph.Source
+	Namespaces     []string
+	AllNamespaces  bool
+	Roots          []graph.Root
+	Filenames      []string
+	Recursive      bool
+	Kustomize      string
+	HelmChart      string
+	HelmRelease    string
+	Save           string
+	FromSnapshot   string
+	Watch          bool
+	Output         string
+	Color          string
+	LabelSelector  string
+	DotGraph       bool
+	ChunkSize      int64
+	MetadataOnly   bool
+	Strict         bool
+	PrintVersion   bool
 }
 
 func init() {
@@ -58,6 +82,9 @@ func NewOptions(iostreams genericclioptions.IOStreams) *Options {
 	return &Options{
 		ConfigFlags: genericclioptions.NewConfigFlags(true),
 		IOStreams:   iostreams,
+		ChunkSize:   graph.DefaultChunkSize,
+		Output:      graph.OutputTree,
+		Color:       graph.ColorAuto,
 	}
 }
 
@@ -66,7 +93,7 @@ func NewCmd(iostreams genericclioptions.IOStreams) *cobra.Command {
 	o := NewOptions(iostreams)
 
 	c := &cobra.Command{
-		Use:   "[KIND] [NAME] [flags]",
+		Use:   "kubegraph [KIND[,KIND...]] [NAME...] | [KIND/NAME...] | [KIND -l label] [flags]",
 		Short: "Print a tree or dot graph to visualize the relationship between kubernetes objects",
 		Example: `
 # Print a tree graph that shows all kubernetes objects that are related to the service service-foo
@@ -74,11 +101,74 @@ kubegraph service service-foo
 
 kubectl graph service service-foo
 
+# Print the tree graph with a green, yellow or red health indicator per object, even when piped to less -R
+kubegraph deploy api --color always | less -R
+
+# Print the tree graph with the ready replicas, status, restarts, age, node, ports and hosts of each object
+kubegraph deploy api -o wide
+
 # Print a DOT graph that shows all kubernetes objects that are related to the ingress ingress-bar
-kubegraph ingress ingress-bar --dot 
+kubegraph ingress ingress-bar -o dot
+
+kubectl graph ingress ingress-bar -o dot
+
+# Print the nodes and edges of the graph of the deployment api as JSON, e.g. to process them with jq
+kubegraph deploy api -o json
+
+# Print a mermaid flowchart of the graph of the service web to embed it in a Markdown document
+kubegraph svc web -o mermaid
+
+# Render a PlantUML or D2 diagram of the graph of the deployment api
+kubegraph deploy api -o plantuml | plantuml -pipe -tsvg > api.svg
+kubegraph deploy api -o d2 | d2 - api.svg
+
+# Export the graph of all the supported objects in the namespace shop to yEd or Gephi, or to Cytoscape
+kubegraph --namespace shop -o graphml > shop.graphml
+kubegraph --namespace shop -o cytoscape > shop.cyjs
+
+# Write an interactive HTML page of the graph of the deployment api, to open it in a browser without Graphviz
+kubegraph deploy api -o html > api.html
+
+# Draw an SVG or PNG image of the graph of the service web without Graphviz
+kubegraph svc web -o svg > web.svg
+kubegraph svc web -o png > web.png
+
+# Print one tree graph of the deployment api and the service web
+kubegraph deploy/api svc/web
+
+# Print one tree graph of the deployments api and worker
+kubegraph deploy api worker
+
+# Print a tree graph of the deployment api from the manifests of a directory, without a cluster
+kubegraph deploy api -f ./manifests -R
+
+# Print a tree graph of the objects of a kustomize overlay, without a cluster
+kubegraph -k ./overlays/production
+
+# Print a tree graph of the objects of a helm chart rendered with a values file, without a cluster
+kubegraph --helm-chart ./chart -f values.yaml
+
+# Save the graph of the deployment api to a snapshot file, and print it later as a DOT graph without a cluster
+kubegraph deploy api --save api.json
+kubegraph --from-snapshot api.json -o dot
+
+# Print the graph of the service web from the objects of a snapshot file
+kubegraph svc web --from-snapshot api.json
+
+# Watch the graph of the deployment api during a rolling update
+kubegraph deploy api --watch
+
+# Print a JSON line for every node and edge of the graph of the deployment api that is added, updated or removed
+kubegraph deploy api --watch -o jsonl
+
+# Print a tree graph of all the supported kubernetes objects in the namespace shop
+kubegraph --namespace shop
 
-kubectl graph ingress ingress-bar --dot
+# Print a tree graph of all the deployments with the label team=payments and their related objects
+kubegraph deployment -l team=payments
 `,
+		// Allow the KIND and NAME arguments next to the subcommands
+		Args:         cobra.ArbitraryArgs,
 		SilenceUsage: true,
 		RunE: func(c *cobra.Command, args []string) error {
 			if err := o.Complete(c, args); err != nil {
@@ -95,9 +185,30 @@ kubectl graph ingress ingress-bar --dot
 		},
 	}
 
-	c.Flags().BoolVar(&o.DotGraph, "dot", o.DotGraph, "If true, a DOT graph will be printed to stdout")
+	c.Flags().StringSliceVarP(&o.Filenames, "filename", "f", o.Filenames, "Filename, directory, or - for stdin of the YAML or JSON manifests used to build the graph without a cluster")
+	c.Flags().BoolVarP(&o.Recursive, "recursive", "R", o.Recursive, "Process the directory used in -f, --filename recursively")
+	c.Flags().StringVarP(&o.Kustomize, "kustomize", "k", o.Kustomize, "Process the kustomization directory to build the graph without a cluster")
+	c.Flags().StringVar(&o.HelmChart, "helm-chart", o.HelmChart, "Chart rendered with helm template to build the graph without a cluster, -f, --filename sets its values files")
+	c.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter the objects used as graph roots, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
+	c.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If true, look for the object in all namespaces and group the related objects per namespace")
+	c.Flags().StringSliceVar(&o.Namespaces, "namespaces", o.Namespaces, "Comma separated list of namespaces to look for the object in")
+	c.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch, "After printing the graph, watch the objects and print the graph again, or its changes when the output is not a terminal, every time they change")
+	c.Flags().StringVar(&o.FromSnapshot, "from-snapshot", o.FromSnapshot, "Print the graph saved in the snapshot file with --save, or build the graph of the arguments from its objects, without a cluster")
+	c.Flags().StringVar(&o.Color, "color", o.Color, "Color the objects of the tree and wide outputs by their health. One of: "+strings.Join(graph.ColorModes, "|")+", auto colors them when the output is a terminal")
 	c.Flags().BoolVar(&o.PrintVersion, "version", o.PrintVersion, "Print kubegraph the version")
-	o.ConfigFlags.AddFlags(c.Flags())
+
+	// Flags shared with the subcommands
+	c.PersistentFlags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: "+strings.Join(graph.Outputs, "|")+", or jsonl to print the changes of --watch as JSON lines")
+	c.PersistentFlags().BoolVar(&o.DotGraph, "dot", o.DotGraph, "If true, a DOT graph will be printed to stdout")
+	_ = c.PersistentFlags().MarkDeprecated("dot", "use -o dot instead")
+	c.PersistentFlags().Int64Var(&o.ChunkSize, "chunk-size", o.ChunkSize, "Return large lists in chunks rather than all at once. Pass 0 to disable")
+	c.PersistentFlags().BoolVar(&o.MetadataOnly, "metadata-only", o.MetadataOnly, "If true, only the metadata of the related objects is fetched when their relation does not inspect spec fields")
+	c.PersistentFlags().StringVar(&o.Save, "save", o.Save, "Save the objects and edges of the graph to a snapshot file, to print it later with --from-snapshot")
+	c.PersistentFlags().BoolVar(&o.Strict, "strict", o.Strict, "If true, exit with an error when some related objects could not be listed, e.g. forbidden by RBAC")
+	o.ConfigFlags.AddFlags(c.PersistentFlags())
+
+	c.AddCommand(NewHelmReleaseCmd(o))
+	c.AddCommand(NewDiffCmd(o))
 
 	return c
 }
@@ -106,13 +217,41 @@ kubectl graph ingress ingress-bar --dot
 func (o *Options) Complete(cmd *cobra.Command, args []string) error {
 	klog.V(1).Infoln("add information to Options struct")
 
-	// Get Type and Name
-	if len(args) >= 2 {
-		o.Kind = args[0]
-		o.Name = args[1]
+	// Get Kinds and Names
+	roots, err := graph.NewRoots(args)
+	if err != nil {
+		return err
+	}
+
+	o.Roots = roots
+
+	if o.DotGraph {
+		o.Output = graph.OutputDot
+	}
+
+	// The snapshot holds the whole graph, the graph of the arguments is built from its objects
+	if o.FromSnapshot != "" {
+		if len(o.Filenames) > 0 || o.Kustomize != "" || o.HelmChart != "" {
+			return fmt.Errorf("--from-snapshot can not be used with -f, -k or --helm-chart")
+		}
+
+		if len(args) == 0 {
+			return nil
+		}
+	}
+
+	// Get namespaces. Without -n, --namespaces or -A, the graph of the manifests,
+	// the kustomization or the chart is built in the namespaces of their objects
+	explicitNamespaces := o.AllNamespaces || len(o.Namespaces) > 0 || cmd.Flags().Changed("namespace")
+
+	if o.AllNamespaces {
+		if len(o.Namespaces) > 0 {
+			return fmt.Errorf("--all-namespaces and --namespaces can not be used together")
+		}
+
+		o.Namespaces = []string{metav1.NamespaceAll}
 	}
 
-	// Get namespace
 	namespace, err := cmd.Flags().GetString("namespace")
 	if err != nil {
 		return err
@@ -127,9 +266,83 @@ func (o *Options) Complete(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	o.Namespace = namespace
+	if len(o.Namespaces) == 0 {
+		o.Namespaces = []string{namespace}
+	}
+
+	// Get Source from the snapshot, the chart, the kustomization or the manifests,
+	// the objects without a namespace are added to the default namespace
+	if o.FromSnapshot != "" {
+		s, err := graph.LoadSnapshot(o.FromSnapshot)
+		if err != nil {
+			return err
+		}
+
+		snapshotSource := graph.NewSnapshotSource(s)
+
+		o.Source = snapshotSource
+
+		if !explicitNamespaces && len(snapshotSource.GetNamespaces()) > 0 {
+			o.Namespaces = snapshotSource.GetNamespaces()
+		}
+
+		return nil
+	}
+
+	if o.HelmChart != "" {
+		if o.Kustomize != "" || o.Recursive {
+			return fmt.Errorf("--helm-chart can not be used with -k or -R")
+		}
+
+		chartSource, err := graph.NewHelmChartSource(o.HelmChart, o.Filenames, namespace)
+		if err != nil {
+			return err
+		}
+
+		o.Source = chartSource
+
+		if !explicitNamespaces && len(chartSource.GetNamespaces()) > 0 {
+			o.Namespaces = chartSource.GetNamespaces()
+		}
+
+		return nil
+	}
+
+	if o.Kustomize != "" {
+		if len(o.Filenames) > 0 || o.Recursive {
+			return fmt.Errorf("-k can not be used with -f or -R")
+		}
+
+		kustomizeSource, err := graph.NewKustomizeSource(o.Kustomize, namespace)
+		if err != nil {
+			return err
+		}
+
+		o.Source = kustomizeSource
+
+		if !explicitNamespaces && len(kustomizeSource.GetNamespaces()) > 0 {
+			o.Namespaces = kustomizeSource.GetNamespaces()
+		}
+
+		return nil
+	}
+
+	if len(o.Filenames) > 0 {
+		manifestSource, err := graph.NewManifestSource(o.Filenames, o.Recursive, namespace)
+		if err != nil {
+			return err
+		}
 
-	// Get Client
+		o.Source = manifestSource
+
+		if !explicitNamespaces && len(manifestSource.GetNamespaces()) > 0 {
+			o.Namespaces = manifestSource.GetNamespaces()
+		}
+
+		return nil
+	}
+
+	// Get Source from the cluster
 	restConfig, err := o.ConfigFlags.ToRESTConfig()
 	if err != nil {
 		return err
@@ -141,6 +354,16 @@ func (o *Options) Complete(cmd *cobra.Command, args []string) error {
 	}
 
 	o.Client = dynClient
+	o.Source = graph.NewDynamicSource(dynClient)
+
+	if o.MetadataOnly {
+		metadataClient, err := metadata.NewForConfig(restConfig)
+		if err != nil {
+			return err
+		}
+
+		o.MetadataSource = graph.NewMetadataSource(metadataClient)
+	}
 
 	return nil
 }
@@ -149,8 +372,53 @@ func (o *Options) Complete(cmd *cobra.Command, args []string) error {
 func (o *Options) Validate(args []string) error {
 	klog.V(1).Infoln("validate arguments")
 
-	if len(args) != 2 && !o.PrintVersion {
-		return fmt.Errorf("requires valid 'kind' and 'name' arguments")
+	for _, r := range o.Roots {
+		if r.Name != "" && o.LabelSelector != "" {
+			return fmt.Errorf("name cannot be provided when a selector is specified")
+		}
+	}
+
+	if _, err := labels.Parse(o.LabelSelector); err != nil {
+		return err
+	}
+
+	if o.ChunkSize < 0 {
+		return fmt.Errorf("chunk-size must be 0 or greater")
+	}
+
+	if o.Watch && o.Client == nil {
+		return fmt.Errorf("--watch requires a cluster, it can not be used with -f, -k, --helm-chart or --from-snapshot")
+	}
+
+	if o.Watch && o.Save != "" {
+		return fmt.Errorf("--watch and --save can not be used together")
+	}
+
+	// The informers cache the full objects, and the watch does not stop on incomplete graphs
+	if o.Watch && (o.MetadataOnly || o.Strict) {
+		return fmt.Errorf("--watch can not be used with --metadata-only or --strict")
+	}
+
+	if o.Output == graph.OutputJSONLines && !o.Watch {
+		return fmt.Errorf("output format 'jsonl' requires --watch")
+	}
+
+	// The graph is redrawn in a terminal, otherwise its changes are printed as lines or JSON lines
+	if o.Watch && !graph.Contains(o.Output, []string{graph.OutputTree, graph.OutputWide, graph.OutputJSONLines}) {
+		return fmt.Errorf("output format '%s' not supported with --watch, one of: %s|%s|%s", o.Output, graph.OutputTree, graph.OutputWide, graph.OutputJSONLines)
+	}
+
+	if o.Output != graph.OutputJSONLines && !graph.Contains(o.Output, graph.Outputs) {
+		return fmt.Errorf("output format '%s' not supported, one of: %s|%s", o.Output, strings.Join(graph.Outputs, "|"), graph.OutputJSONLines)
+	}
+
+	if !graph.Contains(o.Color, graph.ColorModes) {
+		return fmt.Errorf("color mode '%s' not supported, one of: %s", o.Color, strings.Join(graph.ColorModes, "|"))
+	}
+
+	// The PNG image would garble the terminal
+	if f, ok := o.Out.(*os.File); ok && o.Output == graph.OutputPNG && term.IsTerminal(int(f.Fd())) {
+		return fmt.Errorf("output format 'png' can not be printed to a terminal, redirect it to a file")
 	}
 
 	return nil
@@ -162,15 +430,115 @@ func (o *Options) Run() error {
 
 	if o.PrintVersion {
 		fmt.Printf("Version:\t%s\nBranch:\t\t%s\nCommit:\t\t%s\nGo Version:\t%s\nOS/Arch:\t%s\nDate:\t\t%s\n", internal.Version, internal.Branch, internal.Commit, internal.GoVersion, internal.OSArch, internal.Date)
+	} else if o.Watch {
+		return o.watch()
 	} else {
-		b := graph.NewBuilder(o.Client, o.Out, o.DotGraph, o.Namespace, o.Kind, o.Name)
-
-		err := b.Build()
+		objsData, warnings, err := o.getGraph()
 		if err != nil {
 			return err
 		}
 
+		if o.Save != "" {
+			err := graph.NewSnapshot(objsData, warnings).Save(o.Save)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(objsData) == 0 {
+			fmt.Fprintln(o.ErrOut, "No resources found")
+		}
+
+		for _, w := range warnings {
+			fmt.Fprintf(o.ErrOut, "Warning: %s\n", w)
+		}
+
+		if o.Strict && len(warnings) > 0 {
+			return fmt.Errorf("the graph is incomplete, %d warning(s) found", len(warnings))
+		}
 	}
 
 	return nil
 }
+
+// getGraph prints the graph built from the source or loaded from the snapshot,
+// and returns its objects data and warnings
+func (o *Options) getGraph() ([]graph.ObjData, []string, error) {
+	if o.FromSnapshot != "" && o.Source == nil {
+		s, err := graph.LoadSnapshot(o.FromSnapshot)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		objsData, err := s.GetObjsData()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		p := graph.NewPrinter(objsData, o.Output, o.Out)
+		p.Color = o.useColor()
+
+		err = p.Print()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return objsData, s.Warnings, nil
+	}
+
+	if o.HelmRelease != "" {
+		roots, namespace, err := graph.NewHelmReleaseRoots(o.Source, o.Namespaces, o.HelmRelease, o.ChunkSize)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The related objects are looked for in the namespace of the release
+		o.Roots = roots
+		o.Namespaces = []string{namespace}
+	}
+
+	b := graph.NewBuilder(o.Source, o.Out, o.Output, o.Namespaces, o.Roots)
+	b.ChunkSize = o.ChunkSize
+	b.MetadataSource = o.MetadataSource
+	b.LabelSelector = o.LabelSelector
+	b.Color = o.useColor()
+
+	err := b.Build()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return b.ObjsData, b.Warnings, nil
+}
+
+// watch prints the graph every time the objects change until an interrupt signal is received.
+// The terminal is redrawn, or the changes are printed when the output is not a terminal.
+func (o *Options) watch() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w := graph.NewWatcher(o.Client, o.Out, o.Output, o.Namespaces, o.Roots)
+	w.LabelSelector = o.LabelSelector
+	w.Color = o.useColor()
+
+	if f, ok := o.Out.(*os.File); ok && o.Output != graph.OutputJSONLines {
+		w.Redraw = term.IsTerminal(int(f.Fd()))
+	}
+
+	return w.Watch(ctx)
+}
+
+// useColor returns true when the tree and wide outputs are colored: always, or auto
+// when the output is a terminal and the NO_COLOR environment variable is not set
+func (o *Options) useColor() bool {
+	switch o.Color {
+	case graph.ColorAlways:
+		return true
+	case graph.ColorAuto:
+		f, ok := o.Out.(*os.File)
+
+		return ok && os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(f.Fd()))
+	}
+
+	return false
+}
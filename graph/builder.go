@@ -7,23 +7,43 @@ import (
 
 	"strings"
 
-	"k8s.io/client-go/dynamic"
-
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/klog/v2"
 )
 
+// DefaultChunkSize is the default number of objects requested per list call
+const DefaultChunkSize int64 = 500
+
 // Builder holds the information to build the graph
 type Builder struct {
-	Client    dynamic.Interface
-	Namespace string
-	Kind      string
-	Name      string
-	Out       io.Writer
-	DotGraph  bool
-	ObjData
+	Source Source
+	// MetadataSource, if set, is used to list only the metadata
+	// of the kinds whose spec fields are not inspected
+	MetadataSource Source
+	Namespaces     []string
+	Roots          []Root
+	LabelSelector  string
+	Out            io.Writer
+	Output         string
+	ChunkSize      int64
+	Warnings       []string
+	ObjsData       []ObjData
+	// Color adds the health indicators to the tree and wide outputs
+	Color bool
+}
+
+// Root holds the kind and name of the main objects of the graph.
+// An empty kind means all the supported kinds and an empty name
+// all the objects of the kind that match the label selector.
+// An optional root that can not be read is skipped with a warning.
+type Root struct {
+	Kind     string
+	Name     string
+	Optional bool
 }
 
 // ObjData holds the object and related objects data
@@ -31,71 +51,363 @@ type ObjData struct {
 	Obj             unstructured.Unstructured
 	Hierarchy       string
 	RelatedObjsData []ObjData
+	// Unknown holds the reason why the related objects of this kind
+	// could not be listed, e.g. forbidden. Obj only holds the kind then.
+	Unknown string
 }
 
-// NewBuilder returns a new builder struct
-func NewBuilder(client dynamic.Interface, out io.Writer, dotGraph bool, namespace, kind, name string) *Builder {
+// NewBuilder returns a new builder struct. An empty namespace
+// in the namespaces list means all namespaces.
+func NewBuilder(source Source, out io.Writer, output string, namespaces []string, roots []Root) *Builder {
 	return &Builder{
-		Client:    client,
-		Out:       out,
-		DotGraph:  dotGraph,
-		Namespace: namespace,
-		Kind:      kind,
-		Name:      name,
-		ObjData:   ObjData{},
+		Source:     source,
+		Out:        out,
+		Output:     output,
+		Namespaces: namespaces,
+		Roots:      roots,
+		ChunkSize:  DefaultChunkSize,
+		ObjsData:   []ObjData{},
 	}
 }
 
+// NewRoots returns the roots of the graph from kubectl like arguments:
+// KIND/NAME pairs, a KIND followed by names, or comma separated kinds
+// followed by names. Without arguments, all the supported kinds are used.
+func NewRoots(args []string) ([]Root, error) {
+	roots := []Root{}
+
+	if len(args) == 0 {
+		return append(roots, Root{}), nil
+	}
+
+	if strings.Contains(args[0], "/") {
+		for _, a := range args {
+			kindName := strings.SplitN(a, "/", 2)
+			if len(kindName) != 2 || kindName[0] == "" || kindName[1] == "" {
+				return roots, fmt.Errorf("argument '%s' must be in the KIND/NAME form when the first argument is in that form", a)
+			}
+
+			roots = append(roots, Root{Kind: getKind(kindName[0]), Name: kindName[1]})
+		}
+
+		return roots, nil
+	}
+
+	for _, k := range strings.Split(args[0], ",") {
+		if k == "" {
+			return roots, fmt.Errorf("argument '%s' holds an empty kind", args[0])
+		}
+
+		if len(args) == 1 {
+			roots = append(roots, Root{Kind: getKind(k)})
+			continue
+		}
+
+		for _, n := range args[1:] {
+			if strings.Contains(n, "/") {
+				return roots, fmt.Errorf("there is no need to specify a kind as a separate argument when passing arguments in KIND/NAME form (e.g. 'kubegraph deploy/foo' instead of 'kubegraph deploy deploy/foo')")
+			}
+
+			roots = append(roots, Root{Kind: getKind(k), Name: n})
+		}
+	}
+
+	return roots, nil
+}
+
 // Build gets all the information required to build the graph
 func (b *Builder) Build() error {
-	klog.V(1).Infoln("get objects to build the graph")
-
-	o, err := getObject(b.Client, b.Namespace, b.Kind, b.Name)
+	err := b.Collect()
 	if err != nil {
 		return err
 	}
-	b.ObjData.Obj = o
-	b.ObjData.Hierarchy = ""
 
-	r, err := getRelatedObjects(b.Client, []string{}, b.ObjData.Obj, b.Namespace)
+	p := NewPrinter(b.ObjsData, b.Output, b.Out)
+	p.Color = b.Color
+	err = p.Print()
 	if err != nil {
 		return err
 	}
-	b.ObjData.RelatedObjsData = r
 
-	klog.V(4).Infof("object data JSON %s", ToJSON(b.ObjData))
+	return nil
+}
+
+// Collect gets the objects of the roots and their related objects into ObjsData, without printing them
+func (b *Builder) Collect() error {
+	klog.V(1).Infoln("get objects to build the graph")
 
-	p := NewPrinter(b.ObjData, b.DotGraph, b.Out)
-	p.Print()
+	objs, err := b.getObjects()
 	if err != nil {
 		return err
 	}
 
+	// objects already shown in the graph of a previous main object
+	// are not used as main objects to avoid duplicated graphs
+	processedIDs := map[string]bool{}
+
+	for _, o := range objs {
+		if processedIDs[GetObjID(o)] {
+			klog.V(2).Infof("main object '%s' already in the graph, skip", GetObjID(o))
+			continue
+		}
+
+		r, err := b.getRelatedObjects([]string{}, o)
+		if err != nil {
+			return err
+		}
+
+		objData := ObjData{
+			Obj:             o,
+			Hierarchy:       "",
+			RelatedObjsData: r,
+		}
+		addObjIDs(objData, processedIDs)
+
+		b.ObjsData = append(b.ObjsData, objData)
+	}
+
+	b.ObjsData = mergeObjsData(b.ObjsData)
+
+	klog.V(4).Infof("object data JSON %s", ToJSON(b.ObjsData))
+
 	return nil
 }
 
-// getObject returns the requested object
-func getObject(client dynamic.Interface, namespace, kind, name string) (unstructured.Unstructured, error) {
-	klog.V(1).Infof("get main object '%s'", kind)
-	klog.V(2).Infof("get main object '%s' has finished", kind)
+// getObjects returns the main objects of all the roots
+func (b *Builder) getObjects() ([]unstructured.Unstructured, error) {
+	objs := []unstructured.Unstructured{}
 
-	gvr, err := getGroupVersionResource(kind)
-	if err != nil {
-		return unstructured.Unstructured{}, err
+	for _, r := range b.Roots {
+		o, err := b.getRootObjects(r)
+		if err != nil {
+			reason := getUnknownReason(err)
+			if !r.Optional || reason == "" {
+				return objs, err
+			}
+
+			b.addWarning(fmt.Sprintf("'%s/%s' could not be read: %s", r.Kind, r.Name, reason))
+			continue
+		}
+
+		objs = append(objs, o...)
 	}
 
-	ri := client.Resource(gvr).Namespace(namespace)
+	return objs, nil
+}
 
-	obj, err := ri.Get(context.TODO(), name, metav1.GetOptions{})
-	if err != nil {
-		return unstructured.Unstructured{}, err
+// getRootObjects returns the main objects of a root. Without a kind, the objects of
+// all the supported kinds are returned, and without a name, all the objects of the
+// kind that match the label selector. When more than one namespace is requested,
+// the namespaces without the named object are skipped.
+func (b *Builder) getRootObjects(root Root) ([]unstructured.Unstructured, error) {
+	klog.V(1).Infof("get main objects of kind '%s'", root.Kind)
+	defer klog.V(2).Infof("get main objects of kind '%s' has finished", root.Kind)
+
+	objs := []unstructured.Unstructured{}
+	f := NewFilter()
+
+	kinds := []string{root.Kind}
+	if root.Kind == "" {
+		kinds = supportedKinds
 	}
 
-	return *obj, nil
+	for _, kind := range kinds {
+		gvr, err := getGroupVersionResource(kind)
+		if err != nil {
+			return objs, err
+		}
+
+		for _, namespace := range b.Namespaces {
+			if root.Name == "" {
+				opts := metav1.ListOptions{LabelSelector: b.LabelSelector}
+
+				l, err := b.listObjects(f, gvr, namespace, kind, []string{}, opts, func(unstructured.Unstructured) bool { return true })
+				if err != nil {
+					reason := getUnknownReason(err)
+					if reason == "" {
+						return objs, err
+					}
+
+					b.addWarning(fmt.Sprintf("'%s' in namespace '%s' could not be listed: %s", gvr.GroupResource(), namespace, reason))
+					continue
+				}
+
+				objs = append(objs, l...)
+				continue
+			}
+
+			if namespace == metav1.NamespaceAll {
+				opts := metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", root.Name).String()}
+
+				l, err := b.listObjects(f, gvr, namespace, kind, []string{}, opts, func(o unstructured.Unstructured) bool { return o.GetName() == root.Name })
+				if err != nil {
+					return objs, err
+				}
+
+				objs = append(objs, l...)
+				continue
+			}
+
+			obj, err := b.Source.Get(context.TODO(), gvr, namespace, root.Name)
+			if err != nil {
+				if apierrors.IsNotFound(err) && len(b.Namespaces) > 1 {
+					klog.V(2).Infof("main object not found in namespace '%s', skip", namespace)
+					continue
+				}
+
+				return objs, err
+			}
+
+			objs = append(objs, *obj)
+		}
+
+		if len(objs) == 0 && root.Name != "" {
+			return objs, apierrors.NewNotFound(gvr.GroupResource(), root.Name)
+		}
+	}
+
+	return objs, nil
+}
+
+// addObjIDs adds the IDs of an object and its related objects to the ids map
+func addObjIDs(o ObjData, ids map[string]bool) {
+	if o.Unknown == "" {
+		ids[GetObjID(o.Obj)] = true
+	}
+
+	for _, r := range o.RelatedObjsData {
+		addObjIDs(r, ids)
+	}
+}
+
+// mergeNode holds an object data while the merged trees are built
+type mergeNode struct {
+	ObjData  ObjData
+	ID       string
+	Children []*mergeNode
 }
 
-// getRelatedObjects returns the list of upper and lower related objects
-func getRelatedObjects(client dynamic.Interface, processedObjs []string, obj unstructured.Unstructured, namespace string) ([]ObjData, error) {
+// mergeObjsData merges the graphs of the main objects into one graph where each object is
+// expanded once. The related objects of all the occurrences of an object are joined, and
+// the trees are built again breadth first from the main objects, so each object is placed
+// where it is closest to a main object. A relation to an object placed before adds it
+// again without its related objects. The main objects placed in the tree of a previous
+// main object are removed. The unknown objects are never expanded because they do not
+// stand for a single object.
+func mergeObjsData(objsData []ObjData) []ObjData {
+	objs := map[string]ObjData{}
+	related := map[string][]ObjData{}
+	// relatedIDs holds the IDs of the related objects of each object, to join them in constant time
+	relatedIDs := map[string]map[string]struct{}{}
+
+	addRelated := func(id string, r ObjData, hierarchy string) {
+		rID := getObjDataID(r)
+		if _, found := relatedIDs[id][rID]; found {
+			return
+		}
+
+		if relatedIDs[id] == nil {
+			relatedIDs[id] = map[string]struct{}{}
+		}
+		relatedIDs[id][rID] = struct{}{}
+
+		related[id] = append(related[id], ObjData{Obj: r.Obj, Hierarchy: hierarchy, Unknown: r.Unknown})
+	}
+
+	var add func(o ObjData)
+	add = func(o ObjData) {
+		id := getObjDataID(o)
+		if _, found := objs[id]; !found {
+			objs[id] = ObjData{Obj: o.Obj, Unknown: o.Unknown}
+		}
+
+		for _, r := range o.RelatedObjsData {
+			addRelated(id, r, r.Hierarchy)
+			if r.Unknown == "" && o.Unknown == "" {
+				addRelated(getObjDataID(r), o, getOppositeHierarchy(r.Hierarchy))
+			}
+
+			add(r)
+		}
+	}
+
+	for _, o := range objsData {
+		add(o)
+	}
+
+	placed := map[string]bool{}
+	linked := map[string]bool{}
+	merged := []ObjData{}
+
+	for _, o := range objsData {
+		id := getObjDataID(o)
+		if placed[id] {
+			klog.V(2).Infof("main object '%s' merged in the graph of a previous main object", id)
+			continue
+		}
+
+		root := &mergeNode{ObjData: objs[id], ID: id}
+		placed[id] = true
+		queue := []*mergeNode{root}
+
+		for len(queue) > 0 {
+			n := queue[0]
+			queue = queue[1:]
+
+			for _, r := range related[n.ID] {
+				rID := getObjDataID(r)
+
+				link := n.ID + "\n" + rID
+				if rID < n.ID {
+					link = rID + "\n" + n.ID
+				}
+
+				if linked[link] {
+					continue
+				}
+				linked[link] = true
+
+				child := &mergeNode{ObjData: r, ID: rID}
+				n.Children = append(n.Children, child)
+
+				if !placed[rID] && r.Unknown == "" {
+					placed[rID] = true
+					queue = append(queue, child)
+				}
+			}
+		}
+
+		merged = append(merged, getMergedObjData(root))
+	}
+
+	return merged
+}
+
+// getMergedObjData returns the object data of a merged tree
+func getMergedObjData(n *mergeNode) ObjData {
+	o := n.ObjData
+	o.RelatedObjsData = []ObjData{}
+
+	for _, c := range n.Children {
+		o.RelatedObjsData = append(o.RelatedObjsData, getMergedObjData(c))
+	}
+
+	return o
+}
+
+// getOppositeHierarchy returns the hierarchy of an object seen from its related object
+func getOppositeHierarchy(hierarchy string) string {
+	if hierarchy == "upper" {
+		return "lower"
+	}
+
+	return "upper"
+}
+
+// getRelatedObjects returns the list of upper and lower related objects.
+// Kinds that can not be listed because of a forbidden or not found error
+// are added as unknown objects and reported as warnings.
+func (b *Builder) getRelatedObjects(processedObjs []string, obj unstructured.Unstructured) ([]ObjData, error) {
 	klog.V(1).Infof("get related objects of kind '%s'", obj.GetKind())
 	defer klog.V(2).Infof("get related objects of kind '%s' has finished", obj.GetKind())
 
@@ -104,10 +416,11 @@ func getRelatedObjects(client dynamic.Interface, processedObjs []string, obj uns
 	relatedKinds := getRelatedKinds(strings.ToLower(obj.GetKind()))
 	processedObjs = append(processedObjs, strings.ToLower(obj.GetKind()))
 
-	for hierarchy, kinds := range relatedKinds {
+	// The upper kinds are processed first so the related objects are always in the same order
+	for _, hierarchy := range []string{"upper", "lower"} {
 		klog.V(2).Infof("'%s' hierarchy '%s'", obj.GetKind(), hierarchy)
 
-		for _, k := range kinds {
+		for _, k := range relatedKinds[hierarchy] {
 			klog.V(2).Infof("related object kind '%s'", k)
 			// avoid calling the same obj multiple times
 			if Contains(k, processedObjs) {
@@ -120,34 +433,189 @@ func getRelatedObjects(client dynamic.Interface, processedObjs []string, obj uns
 				return relatedObjs, err
 			}
 
-			ri := client.Resource(gvr).Namespace(namespace)
-
-			objList, err := ri.List(context.TODO(), metav1.ListOptions{})
+			objs, err := b.listRelatedObjects(f, gvr, obj, k, processedObjs)
 			if err != nil {
-				return relatedObjs, err
+				reason := getUnknownReason(err)
+				if reason == "" {
+					return relatedObjs, err
+				}
+
+				b.addWarning(fmt.Sprintf("'%s' in namespace '%s' could not be listed: %s", gvr.GroupResource(), obj.GetNamespace(), reason))
+				relatedObjs = append(relatedObjs, newUnknownObjData(k, obj.GetNamespace(), hierarchy, reason))
+				continue
 			}
 
-			for _, o := range objList.Items {
-				klog.V(2).Infof("filter related object '%s %s'", o.GetKind(), o.GetName())
-				if f.FilterObj(obj, o) {
-					klog.V(2).Infof("OK")
-					r := ObjData{}
-					r.Obj = o
-					r.Hierarchy = hierarchy
-					innerRelatedObjs, err := getRelatedObjects(client, processedObjs, o, namespace)
-					if err != nil {
-						return relatedObjs, err
-					}
-					r.RelatedObjsData = innerRelatedObjs
-					relatedObjs = append(relatedObjs, r)
+			for _, o := range objs {
+				r := ObjData{}
+				r.Obj = o
+				r.Hierarchy = hierarchy
+				innerRelatedObjs, err := b.getRelatedObjects(processedObjs, o)
+				if err != nil {
+					return relatedObjs, err
 				}
+				r.RelatedObjsData = innerRelatedObjs
+				relatedObjs = append(relatedObjs, r)
 			}
 		}
 	}
 
+	// The service of an external name is only followed once to avoid loops of ExternalName services
+	if name, namespace, ok := getExternalNameService(obj); ok && !Contains(externalNameHop, processedObjs) {
+		r, err := b.getExternalNameObjsData(processedObjs, name, namespace)
+		if err != nil {
+			return relatedObjs, err
+		}
+
+		relatedObjs = append(relatedObjs, r...)
+	}
+
 	return relatedObjs, nil
 }
 
+// externalNameHop is added to the processed kinds after following the external name of a service
+const externalNameHop = "externalname"
+
+// getExternalNameObjsData returns the service an ExternalName service points to, with its
+// related objects. The service may be in another namespace. Nothing is returned when the
+// service does not exist, because the external name may be resolved outside the cluster.
+func (b *Builder) getExternalNameObjsData(processedObjs []string, name, namespace string) ([]ObjData, error) {
+	klog.V(1).Infof("get service '%s' of external name in namespace '%s'", name, namespace)
+
+	gvr, err := getGroupVersionResource("service")
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := b.Source.Get(context.TODO(), gvr, namespace, name)
+	if apierrors.IsNotFound(err) {
+		klog.V(2).Infof("service '%s' of external name not found in namespace '%s', skip", name, namespace)
+		return []ObjData{}, nil
+	}
+
+	if err != nil {
+		reason := getUnknownReason(err)
+		if reason == "" {
+			return nil, err
+		}
+
+		b.addWarning(fmt.Sprintf("'%s' in namespace '%s' could not be listed: %s", gvr.GroupResource(), namespace, reason))
+
+		return []ObjData{newUnknownObjData("service", namespace, "lower", reason)}, nil
+	}
+
+	r, err := b.getRelatedObjects(append(append([]string{}, processedObjs...), externalNameHop), *o)
+	if err != nil {
+		return nil, err
+	}
+
+	return []ObjData{{Obj: *o, Hierarchy: "lower", RelatedObjsData: r}}, nil
+}
+
+// listRelatedObjects returns the objects of a group version resource that are related
+// to the obj. The objects are listed in the namespace of the obj because these relations
+// are namespace scoped, the services of external names are got in their own namespace.
+// The label and field selectors known for the relation are sent to the API server, the
+// objects are then filtered with the Filter. The processed kinds are the kinds already
+// in the path from the main object, including the kind of the obj.
+func (b *Builder) listRelatedObjects(f *Filter, gvr schema.GroupVersionResource, obj unstructured.Unstructured, kind string, processedObjs []string) ([]unstructured.Unstructured, error) {
+	opts, ok := f.GetListOptions(obj, kind)
+	if !ok {
+		klog.V(2).Infof("no '%s' can be related to '%s %s', skip", kind, obj.GetKind(), obj.GetName())
+		return []unstructured.Unstructured{}, nil
+	}
+
+	return b.listObjects(f, gvr, obj.GetNamespace(), kind, processedObjs, opts, func(o unstructured.Unstructured) bool {
+		klog.V(2).Infof("filter related object '%s %s'", o.GetKind(), o.GetName())
+		return f.FilterObj(obj, o)
+	})
+}
+
+// listObjects lists the objects of a group version resource in chunks and returns
+// only the ones accepted by the keep function. The objects are filtered page by page
+// so only the accepted objects are kept in memory.
+func (b *Builder) listObjects(f *Filter, gvr schema.GroupVersionResource, namespace, kind string, processedObjs []string, opts metav1.ListOptions, keep func(unstructured.Unstructured) bool) ([]unstructured.Unstructured, error) {
+	return listChunks(b.getListSource(f, kind, processedObjs), gvr, namespace, opts, b.ChunkSize, func(o unstructured.Unstructured) bool {
+		if !keep(o) {
+			return false
+		}
+
+		klog.V(2).Infof("OK")
+		return true
+	})
+}
+
+// getListSource returns the source used to list the objects of a kind. The metadata source
+// is used, when it is set, for the kinds whose spec fields are not inspected.
+func (b *Builder) getListSource(f *Filter, kind string, processedObjs []string) Source {
+	if b.MetadataSource == nil || requiresSpec(f, kind, processedObjs) {
+		return b.Source
+	}
+
+	klog.V(2).Infof("list metadata of '%s'", kind)
+
+	return b.MetadataSource
+}
+
+// requiresSpec returns true if the objects of a kind must be listed with their spec:
+// the Filter inspects it, or their related kinds that are not processed yet are listed
+// with the selector of their spec, e.g. the pods of a replicaset listed from a deployment
+func requiresSpec(f *Filter, kind string, processedObjs []string) bool {
+	if f.RequiresSpec(kind) {
+		return true
+	}
+
+	relatedKinds := getRelatedKinds(kind)
+
+	for _, k := range append(relatedKinds["upper"], relatedKinds["lower"]...) {
+		if !Contains(k, processedObjs) && f.RequiresParentSpec(kind, k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addWarning adds a warning message only once
+func (b *Builder) addWarning(warning string) {
+	klog.V(1).Infof("warning: %s", warning)
+
+	if !Contains(warning, b.Warnings) {
+		b.Warnings = append(b.Warnings, warning)
+	}
+}
+
+// getUnknownReason returns the reason of a list error that should not stop the build,
+// or an empty string if the error must be returned
+func getUnknownReason(err error) string {
+	switch {
+	case apierrors.IsForbidden(err):
+		return "forbidden"
+	case apierrors.IsNotFound(err):
+		return "not found"
+	}
+
+	return ""
+}
+
+// newUnknownObjData returns the object data of a related kind that could not be listed
+func newUnknownObjData(kind, namespace, hierarchy, reason string) ObjData {
+	o := unstructured.Unstructured{}
+	o.SetKind(getKindName(kind))
+	o.SetNamespace(namespace)
+
+	return ObjData{
+		Obj:             o,
+		Hierarchy:       hierarchy,
+		RelatedObjsData: []ObjData{},
+		Unknown:         reason,
+	}
+}
+
+// supportedKinds holds the supported kinds sorted from the upper to the lower kinds.
+// The main objects are processed in this order so the lower objects are usually
+// already part of the graph of an upper object.
+var supportedKinds = []string{"ingress", "service", "deployment", "statefulset", "daemonset", "replicaset", "pod"}
+
 // getRelatedKinds returns a map of the related upper and lower kinds
 func getRelatedKinds(kind string) map[string][]string {
 	relatedkinds := map[string][]string{}
@@ -188,6 +656,33 @@ func getRelatedKinds(kind string) map[string][]string {
 	return relatedkinds
 }
 
+// getKind returns the lowercase singular kind of a kind, short name or plural name
+func getKind(kind string) string {
+	return strings.ToLower(getKindName(strings.ToLower(kind)))
+}
+
+// getKindName returns the kind name as it is defined in the kubernetes API
+func getKindName(kind string) string {
+	switch kind {
+	case "pod", "po", "pods":
+		return "Pod"
+	case "service", "svc", "services":
+		return "Service"
+	case "ingress", "ing", "ingresses":
+		return "Ingress"
+	case "replicaset", "rs", "replicasets":
+		return "ReplicaSet"
+	case "deployment", "deploy", "deployments":
+		return "Deployment"
+	case "daemonset", "ds", "daemonsets":
+		return "DaemonSet"
+	case "statefulset", "sts", "statefulsets":
+		return "StatefulSet"
+	}
+
+	return kind
+}
+
 // getGroupVersionResource returns the correct group version resource struct
 func getGroupVersionResource(kind string) (schema.GroupVersionResource, error) {
 	switch kind {
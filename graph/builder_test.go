@@ -5,81 +5,81 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/watch"
-	"k8s.io/client-go/dynamic"
 )
 
-type MockClient struct{}
-
-type MockResourceInterface struct {
-	Resource string
+// FakeSource serves the objects of an ObjectSource, returns forbidden errors
+// for the Forbidden resources and records the Listed resources and their ListOptions.
+// MetadataOnly removes all the fields of the listed objects but their metadata.
+type FakeSource struct {
+	*ObjectSource
+	Forbidden    []string
+	MetadataOnly bool
+	Listed       *[]string
+	ListOptions  *[]metav1.ListOptions
 }
 
-func (c MockClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
-	return MockResourceInterface{
-		resource.Resource,
-	}
-}
+// NewFakeSource returns a FakeSource with the mock objects in each namespace
+func NewFakeSource(namespaces ...string) *FakeSource {
+	objs := []unstructured.Unstructured{}
 
-func (r MockResourceInterface) Namespace(string) dynamic.ResourceInterface {
-	return MockResourceInterface{
-		r.Resource,
+	for _, ns := range namespaces {
+		for _, r := range []string{"pods", "services", "statefulsets"} {
+			for _, o := range getMockList(r).Items {
+				o.SetNamespace(ns)
+				objs = append(objs, o)
+			}
+		}
 	}
-}
-
-func (r MockResourceInterface) Create(ctx context.Context, obj *unstructured.Unstructured, options metav1.CreateOptions, subresources ...string) (*unstructured.Unstructured, error) {
-	return nil, nil
-}
 
-func (r MockResourceInterface) Update(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions, subresources ...string) (*unstructured.Unstructured, error) {
-	return nil, nil
+	return &FakeSource{
+		ObjectSource: NewObjectSource(objs),
+		Listed:       &[]string{},
+		ListOptions:  &[]metav1.ListOptions{},
+	}
 }
 
-func (r MockResourceInterface) UpdateStatus(ctx context.Context, obj *unstructured.Unstructured, options metav1.UpdateOptions) (*unstructured.Unstructured, error) {
-	return nil, nil
-}
+func (s *FakeSource) List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	*s.Listed = append(*s.Listed, gvr.Resource)
+	*s.ListOptions = append(*s.ListOptions, opts)
 
-func (r MockResourceInterface) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
-	return nil
-}
+	if Contains(gvr.Resource, s.Forbidden) {
+		return nil, apierrors.NewForbidden(gvr.GroupResource(), "", fmt.Errorf("access denied"))
+	}
 
-func (r MockResourceInterface) DeleteCollection(ctx context.Context, options metav1.DeleteOptions, listOptions metav1.ListOptions) error {
-	return nil
-}
+	l, err := s.ObjectSource.List(ctx, gvr, namespace, opts)
+	if err != nil || !s.MetadataOnly {
+		return l, err
+	}
 
-func (r MockResourceInterface) Get(ctx context.Context, name string, options metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
-	u := unstructured.Unstructured{
-		Object: map[string]interface{}{
-			"kind": "Service",
-			"metadata": map[string]interface{}{
-				"name": "service-foo",
-			},
-			"spec": map[string]interface{}{
-				"selector": map[string]interface{}{
-					"app":     "foo",
-					"version": "v1",
-				},
-			},
-		},
+	for i, o := range l.Items {
+		l.Items[i] = unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": o.GetAPIVersion(),
+			"kind":       o.GetKind(),
+			"metadata":   o.Object["metadata"],
+		}}
 	}
 
-	return &u, nil
+	return l, nil
 }
 
-func (r MockResourceInterface) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
-	switch r.Resource {
+func getMockList(resource string) *unstructured.UnstructuredList {
+	switch resource {
 	case "pods":
 		return &unstructured.UnstructuredList{
 			Items: []unstructured.Unstructured{
 				{
 					Object: map[string]interface{}{
-						"kind": "Pod",
+						"apiVersion": "v1",
+						"kind":       "Pod",
 						"metadata": map[string]interface{}{
 							"name": "pod-foo-1",
 							"labels": map[string]interface{}{
@@ -88,7 +88,9 @@ func (r MockResourceInterface) List(ctx context.Context, opts metav1.ListOptions
 							},
 							"ownerReferences": []interface{}{
 								map[string]interface{}{
-									"uid": "1d1fcfc1-6f23-4578-9b70-8361a733ab26",
+									"kind": "StatefulSet",
+									"name": "statefulset-foo-1",
+									"uid":  "1d1fcfc1-6f23-4578-9b70-8361a733ab26",
 								},
 							},
 						},
@@ -96,7 +98,8 @@ func (r MockResourceInterface) List(ctx context.Context, opts metav1.ListOptions
 				},
 				{
 					Object: map[string]interface{}{
-						"kind": "Pod",
+						"apiVersion": "v1",
+						"kind":       "Pod",
 						"metadata": map[string]interface{}{
 							"name": "pod-foo-2",
 							"labels": map[string]interface{}{
@@ -105,38 +108,65 @@ func (r MockResourceInterface) List(ctx context.Context, opts metav1.ListOptions
 							},
 							"ownerReferences": []interface{}{
 								map[string]interface{}{
-									"uid": "1d1fcfc1-6f23-4578-9b70-8361a733ab26",
+									"kind": "StatefulSet",
+									"name": "statefulset-foo-1",
+									"uid":  "1d1fcfc1-6f23-4578-9b70-8361a733ab26",
 								},
 							},
 						},
 					},
 				},
 			},
-		}, nil
+		}
 
 	case "services":
-		return &unstructured.UnstructuredList{}, nil
+		return &unstructured.UnstructuredList{
+			Items: []unstructured.Unstructured{
+				{
+					Object: map[string]interface{}{
+						"apiVersion": "v1",
+						"kind":       "Service",
+						"metadata": map[string]interface{}{
+							"name": "service-foo",
+						},
+						"spec": map[string]interface{}{
+							"selector": map[string]interface{}{
+								"app":     "foo",
+								"version": "v1",
+							},
+						},
+					},
+				},
+			},
+		}
 	case "ingresses":
-		return &unstructured.UnstructuredList{}, nil
+		return &unstructured.UnstructuredList{}
 	case "replicasets":
-		return &unstructured.UnstructuredList{}, nil
+		return &unstructured.UnstructuredList{}
 	case "deployments", "daemonsets":
-		return &unstructured.UnstructuredList{}, nil
+		return &unstructured.UnstructuredList{}
 	case "statefulsets":
 		return &unstructured.UnstructuredList{
 			Items: []unstructured.Unstructured{
 				{
 					Object: map[string]interface{}{
-						"kind": "Statefulset",
+						"apiVersion": "apps/v1",
+						"kind":       "Statefulset",
 						"metadata": map[string]interface{}{
 							"name": "statefulset-foo-1",
 							"uid":  "1d1fcfc1-6f23-4578-9b70-8361a733ab26",
 						},
+						"spec": map[string]interface{}{
+							"selector": map[string]interface{}{
+								"matchLabels": map[string]interface{}{"app": "foo"},
+							},
+						},
 					},
 				},
 				{
 					Object: map[string]interface{}{
-						"kind": "Statefulset",
+						"apiVersion": "apps/v1",
+						"kind":       "Statefulset",
 						"metadata": map[string]interface{}{
 							"name": "statefulset-foo-2",
 							"uid":  "1d1fcfc1-6f23-4578-9b70-8361a733ab20",
@@ -144,26 +174,334 @@ func (r MockResourceInterface) List(ctx context.Context, opts metav1.ListOptions
 					},
 				},
 			},
-		}, nil
+		}
 	}
 
-	return nil, nil
+	return &unstructured.UnstructuredList{}
 }
 
-func (r MockResourceInterface) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
-	return nil, nil
+func TestBuild(t *testing.T) {
+	o := &bytes.Buffer{}
+
+	b := NewBuilder(NewFakeSource("default"), o, OutputTree, []string{"default"}, []Root{{Kind: "service", Name: "service-foo"}})
+
+	err := b.Build()
+	if err != nil {
+		t.Errorf("Graph could not be created. Error: %q", err)
+	}
+
+	expected := "\n[Service] service-foo\n\t\t┌── [Statefulset] statefulset-foo-1\n\t└── [Pod] pod-foo-1\n\n"
+
+	if o.String() != expected {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), expected)
+	}
 }
 
-func (r MockResourceInterface) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, options metav1.PatchOptions, subresources ...string) (*unstructured.Unstructured, error) {
-	return nil, nil
+func TestBuildForbidden(t *testing.T) {
+	o := &bytes.Buffer{}
+
+	s := NewFakeSource("default")
+	s.Forbidden = []string{"ingresses"}
+
+	b := NewBuilder(s, o, OutputTree, []string{"default"}, []Root{{Kind: "service", Name: "service-foo"}})
+
+	err := b.Build()
+	if err != nil {
+		t.Errorf("Graph could not be created. Error: %q", err)
+	}
+
+	expected := "\n\t┌── [Ingress] unknown (forbidden)\n[Service] service-foo\n\t\t┌── [Statefulset] statefulset-foo-1\n\t└── [Pod] pod-foo-1\n\n"
+
+	if o.String() != expected {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), expected)
+	}
+
+	expectedWarnings := []string{"'ingresses.networking.k8s.io' in namespace 'default' could not be listed: forbidden"}
+
+	if !reflect.DeepEqual(b.Warnings, expectedWarnings) {
+		t.Errorf("Returned warnings were incorrect, got: %v want: %v", b.Warnings, expectedWarnings)
+	}
 }
 
-func TestBuild(t *testing.T) {
+func TestBuildNamespaces(t *testing.T) {
+
+	tests := []struct {
+		Namespaces []string
+		Expected   string
+	}{
+		{
+			[]string{"default", "staging"},
+			"\nNamespace: default\n\n[Service] service-foo\n\t\t┌── [Statefulset] statefulset-foo-1\n\t└── [Pod] pod-foo-1\n\n" +
+				"\nNamespace: staging\n\n[Service] service-foo\n\t\t┌── [Statefulset] statefulset-foo-1\n\t└── [Pod] pod-foo-1\n\n",
+		},
+		{
+			[]string{"staging"},
+			"\n[Service] service-foo\n\t\t┌── [Statefulset] statefulset-foo-1\n\t└── [Pod] pod-foo-1\n\n",
+		},
+		{
+			[]string{""},
+			"\nNamespace: default\n\n[Service] service-foo\n\t\t┌── [Statefulset] statefulset-foo-1\n\t└── [Pod] pod-foo-1\n\n" +
+				"\nNamespace: staging\n\n[Service] service-foo\n\t\t┌── [Statefulset] statefulset-foo-1\n\t└── [Pod] pod-foo-1\n\n",
+		},
+	}
+
+	for _, test := range tests {
+		o := &bytes.Buffer{}
+
+		b := NewBuilder(NewFakeSource("default", "staging"), o, OutputTree, test.Namespaces, []Root{{Kind: "service", Name: "service-foo"}})
+
+		err := b.Build()
+		if err != nil {
+			t.Errorf("Graph could not be created. Error: %q", err)
+		}
+
+		if o.String() != test.Expected {
+			t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), test.Expected)
+		}
+	}
+}
+
+func TestBuildAll(t *testing.T) {
+	o := &bytes.Buffer{}
+
+	b := NewBuilder(NewFakeSource("default"), o, OutputTree, []string{"default"}, []Root{{}})
+
+	err := b.Build()
+	if err != nil {
+		t.Errorf("Graph could not be created. Error: %q", err)
+	}
+
+	// The pod pod-foo-2 is merged in the graph of the service through its statefulset
+	expected := "\n[Service] service-foo\n\t\t┌── [Statefulset] statefulset-foo-1\n\t\t\t└── [Pod] pod-foo-2\n\t└── [Pod] pod-foo-1\n\n" +
+		"\n[Statefulset] statefulset-foo-2\n\n"
+
+	if o.String() != expected {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), expected)
+	}
+}
+
+func TestBuildRoots(t *testing.T) {
 	o := &bytes.Buffer{}
 
-	c := MockClient{}
+	roots := []Root{
+		{Kind: "statefulset", Name: "statefulset-foo-2"},
+		{Kind: "service", Name: "service-foo"},
+		{Kind: "pod", Name: "pod-foo-1"},
+	}
 
-	b := NewBuilder(c, o, false, "default", "service", "service-foo")
+	b := NewBuilder(NewFakeSource("default"), o, OutputTree, []string{"default"}, roots)
+
+	err := b.Build()
+	if err != nil {
+		t.Errorf("Graph could not be created. Error: %q", err)
+	}
+
+	expected := "\n[Statefulset] statefulset-foo-2\n\n" +
+		"\n[Service] service-foo\n\t\t┌── [Statefulset] statefulset-foo-1\n\t└── [Pod] pod-foo-1\n\n"
+
+	if o.String() != expected {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), expected)
+	}
+}
+
+func TestBuildMergedRoots(t *testing.T) {
+	owned := func(o unstructured.Unstructured, uid, ownerKind, ownerName, ownerUID string) unstructured.Unstructured {
+		o.SetUID(types.UID(uid))
+		if ownerKind != "" {
+			o.SetOwnerReferences([]metav1.OwnerReference{{Kind: ownerKind, Name: ownerName, UID: types.UID(ownerUID)}})
+		}
+
+		return o
+	}
+
+	service := newTestObj("v1", "Service", "default", "web", nil)
+	service.Object["spec"] = map[string]interface{}{"selector": map[string]interface{}{"app": "web"}}
+
+	ingress := newTestObj("networking.k8s.io/v1", "Ingress", "default", "web", nil)
+	ingress.Object["spec"] = map[string]interface{}{"rules": []interface{}{
+		map[string]interface{}{"http": map[string]interface{}{"paths": []interface{}{
+			map[string]interface{}{"backend": map[string]interface{}{"service": map[string]interface{}{"name": "web"}}},
+		}}},
+	}}
+
+	source := NewObjectSource([]unstructured.Unstructured{
+		owned(newTestObj("apps/v1", "Deployment", "default", "api", nil), "1", "", "", ""),
+		owned(newTestObj("apps/v1", "ReplicaSet", "default", "api-1", nil), "2", "Deployment", "api", "1"),
+		owned(newTestObj("v1", "Pod", "default", "api-1-a", map[string]string{"app": "web"}), "3", "ReplicaSet", "api-1", "2"),
+		owned(newTestObj("v1", "Pod", "default", "api-1-b", map[string]string{"app": "web"}), "4", "ReplicaSet", "api-1", "2"),
+		service,
+		ingress,
+	})
+
+	tests := []struct {
+		Roots         []Root
+		Expected      string
+		ExpectedNodes int
+	}{
+		{
+			// The service shared by the pods is printed once, with both pods
+			[]Root{{Kind: "pod", Name: "api-1-a"}, {Kind: "pod", Name: "api-1-b"}},
+			"\n\t\t┌── [Deployment] api\n\t┌── [ReplicaSet] api-1\n\t\t└── [Pod] api-1-b\n" +
+				"\t\t┌── [Ingress] web\n\t┌── [Service] web\n\t\t└── [Pod] api-1-b\n[Pod] api-1-a\n\n",
+			6,
+		},
+		{
+			// The service is expanded under the first pod and deduped under the second one
+			[]Root{{Kind: "deployment", Name: "api"}},
+			"\n[Deployment] api\n\t└── [ReplicaSet] api-1\n" +
+				"\t\t\t\t┌── [Ingress] web\n\t\t\t┌── [Service] web\n\t\t└── [Pod] api-1-a\n" +
+				"\t\t\t┌── [Service] web (deduped)\n\t\t└── [Pod] api-1-b\n\n",
+			6,
+		},
+	}
+
+	for _, test := range tests {
+		o := &bytes.Buffer{}
+
+		b := NewBuilder(source, o, OutputTree, []string{"default"}, test.Roots)
+
+		err := b.Build()
+		if err != nil {
+			t.Fatalf("Graph could not be created. Error: %q", err)
+		}
+
+		if o.String() != test.Expected {
+			t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), test.Expected)
+		}
+
+		if n := len(NewGraph(b.ObjsData).Nodes); n != test.ExpectedNodes {
+			t.Errorf("Returned number of nodes was incorrect, got: %d want: %d", n, test.ExpectedNodes)
+		}
+	}
+}
+
+func TestBuildExternalName(t *testing.T) {
+	service := func(namespace, name string, spec map[string]interface{}) unstructured.Unstructured {
+		o := newTestObj("v1", "Service", namespace, name, nil)
+		o.Object["spec"] = spec
+
+		return o
+	}
+
+	source := NewObjectSource([]unstructured.Unstructured{
+		service("shop", "db", map[string]interface{}{"type": "ExternalName", "externalName": "db.data.svc.cluster.local"}),
+		service("shop", "mail", map[string]interface{}{"type": "ExternalName", "externalName": "smtp.example.com"}),
+		service("data", "db", map[string]interface{}{"selector": map[string]interface{}{"app": "db"}}),
+		newTestObj("v1", "Pod", "data", "db-0", map[string]string{"app": "db"}),
+	})
+
+	tests := []struct {
+		Output   string
+		Expected string
+	}{
+		{
+			OutputTree,
+			"\n[Service] db\n\t└── [Service] db\n\t\t└── [Pod] db-0\n\n\n[Service] mail\n\n",
+		},
+		{
+			OutputMermaid,
+			`flowchart LR
+    subgraph ns_data["data"]
+        n1{{"Service: db"}}
+        n2("Pod: db-0")
+    end
+    subgraph ns_shop["shop"]
+        n0{{"Service: db"}}
+        n3{{"Service: mail"}}
+    end
+    n0 -->|externalName| n1
+    n1 -->|labelSelector| n2
+`,
+		},
+	}
+
+	for _, test := range tests {
+		o := &bytes.Buffer{}
+
+		b := NewBuilder(source, o, test.Output, []string{"shop"}, []Root{{Kind: "service"}})
+
+		err := b.Build()
+		if err != nil {
+			t.Fatalf("Graph could not be created. Error: %q", err)
+		}
+
+		if o.String() != test.Expected {
+			t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), test.Expected)
+		}
+	}
+}
+
+func TestNewRoots(t *testing.T) {
+
+	tests := []struct {
+		Args     []string
+		Expected []Root
+		Error    bool
+	}{
+		{
+			[]string{},
+			[]Root{{}},
+			false,
+		},
+		{
+			[]string{"deploy"},
+			[]Root{{Kind: "deployment"}},
+			false,
+		},
+		{
+			[]string{"deploy", "api", "worker"},
+			[]Root{{Kind: "deployment", Name: "api"}, {Kind: "deployment", Name: "worker"}},
+			false,
+		},
+		{
+			[]string{"deploy/api", "svc/web"},
+			[]Root{{Kind: "deployment", Name: "api"}, {Kind: "service", Name: "web"}},
+			false,
+		},
+		{
+			[]string{"Deployments,svc", "api"},
+			[]Root{{Kind: "deployment", Name: "api"}, {Kind: "service", Name: "api"}},
+			false,
+		},
+		{
+			[]string{"deploy/api", "web"},
+			[]Root{},
+			true,
+		},
+		{
+			[]string{"deploy", "svc/web"},
+			[]Root{},
+			true,
+		},
+		{
+			[]string{"deploy,", "api"},
+			[]Root{},
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		r, err := NewRoots(test.Args)
+
+		if (err != nil) != test.Error {
+			t.Errorf("Returned error was incorrect for %v, got: %v", test.Args, err)
+			continue
+		}
+
+		if err == nil && !reflect.DeepEqual(r, test.Expected) {
+			t.Errorf("Returned result was incorrect, got: %v want: %v", r, test.Expected)
+		}
+	}
+}
+
+func TestBuildMetadataOnly(t *testing.T) {
+	o := &bytes.Buffer{}
+
+	s := NewFakeSource("default")
+	s.MetadataOnly = true
+
+	b := NewBuilder(NewFakeSource("default"), o, OutputTree, []string{"default"}, []Root{{Kind: "service", Name: "service-foo"}})
+	b.MetadataSource = s
 
 	err := b.Build()
 	if err != nil {
@@ -175,6 +513,126 @@ func TestBuild(t *testing.T) {
 	if o.String() != expected {
 		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), expected)
 	}
+
+	resources := *s.Listed
+	sort.Strings(resources)
+	expectedResources := []string{"pods", "statefulsets"}
+
+	if !reflect.DeepEqual(resources, expectedResources) {
+		t.Errorf("Returned metadata resources were incorrect, got: %v want: %v", resources, expectedResources)
+	}
+}
+
+func TestBuildMetadataOnlyParentSpec(t *testing.T) {
+	s := NewFakeSource("default")
+	m := NewFakeSource("default")
+	m.MetadataOnly = true
+
+	b := NewBuilder(s, &bytes.Buffer{}, OutputTree, []string{"default"}, []Root{{Kind: "statefulset"}})
+	b.MetadataSource = m
+
+	err := b.Build()
+	if err != nil {
+		t.Errorf("Graph could not be created. Error: %q", err)
+	}
+
+	// The statefulsets hold the selector of their pods, so they are listed with their spec
+	if !Contains("statefulsets", *s.Listed) || Contains("statefulsets", *m.Listed) {
+		t.Errorf("Statefulsets were listed with the metadata source, got: %v", *m.Listed)
+	}
+
+	expectedOptions := []metav1.ListOptions{
+		{LabelSelector: "app=foo", Limit: DefaultChunkSize},
+		{LabelSelector: "", Limit: DefaultChunkSize},
+	}
+
+	if !reflect.DeepEqual(*m.Listed, []string{"pods", "pods"}) || !reflect.DeepEqual(*m.ListOptions, expectedOptions) {
+		t.Errorf("Returned metadata list options were incorrect, got: %v %+v want: %+v", *m.Listed, *m.ListOptions, expectedOptions)
+	}
+}
+
+func TestListRelatedObjects(t *testing.T) {
+	tests := []struct {
+		Obj             unstructured.Unstructured
+		Kind            string
+		ExpectedNames   []string
+		ExpectedOptions metav1.ListOptions
+	}{
+		{
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"kind": "Statefulset",
+					"metadata": map[string]interface{}{
+						"name": "statefulset-foo-1",
+						"uid":  "1d1fcfc1-6f23-4578-9b70-8361a733ab26",
+					},
+					"spec": map[string]interface{}{
+						"selector": map[string]interface{}{
+							"matchLabels": map[string]interface{}{"app": "foo"},
+						},
+					},
+				},
+			},
+			"pod",
+			[]string{"pod-foo-1", "pod-foo-2"},
+			metav1.ListOptions{LabelSelector: "app=foo"},
+		},
+		{
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"kind": "Pod",
+					"metadata": map[string]interface{}{
+						"name": "pod-foo-1",
+						"ownerReferences": []interface{}{
+							map[string]interface{}{
+								"kind": "StatefulSet",
+								"name": "statefulset-foo-1",
+								"uid":  "1d1fcfc1-6f23-4578-9b70-8361a733ab26",
+							},
+						},
+					},
+				},
+			},
+			"statefulset",
+			[]string{"statefulset-foo-1"},
+			metav1.ListOptions{FieldSelector: "metadata.name=statefulset-foo-1"},
+		},
+	}
+
+	for _, test := range tests {
+		gvr, _ := getGroupVersionResource(test.Kind)
+
+		for _, chunkSize := range []int64{0, 1, 500} {
+			s := NewFakeSource("default")
+			b := NewBuilder(s, &bytes.Buffer{}, OutputTree, []string{"default"}, []Root{})
+			b.ChunkSize = chunkSize
+
+			r, err := b.listRelatedObjects(NewFilter(), gvr, test.Obj, test.Kind, []string{strings.ToLower(test.Obj.GetKind())})
+			if err != nil {
+				t.Errorf("Related objects could not be listed. Error: %q", err)
+			}
+
+			names := []string{}
+			for _, o := range r {
+				names = append(names, o.GetName())
+			}
+
+			if !reflect.DeepEqual(names, test.ExpectedNames) {
+				t.Errorf("Returned result was incorrect for chunk size %d, got: %v want: %v", chunkSize, names, test.ExpectedNames)
+			}
+
+			// The selectors are sent in every chunk request
+			for _, opts := range *s.ListOptions {
+				if opts.LabelSelector != test.ExpectedOptions.LabelSelector || opts.FieldSelector != test.ExpectedOptions.FieldSelector || opts.Limit != chunkSize {
+					t.Errorf("Returned list options were incorrect for chunk size %d, got: %+v want: %+v", chunkSize, opts, test.ExpectedOptions)
+				}
+			}
+
+			if len(*s.ListOptions) == 0 {
+				t.Errorf("Related objects of kind '%s' were not listed", test.Kind)
+			}
+		}
+	}
 }
 
 func TestGetRelatedKinds(t *testing.T) {
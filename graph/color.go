@@ -0,0 +1,45 @@
+package graph
+
+// Color modes of the tree and wide outputs: auto colors them when they are printed to a terminal
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// ColorModes holds the supported color modes
+var ColorModes = []string{ColorAuto, ColorAlways, ColorNever}
+
+// ANSI escape codes of the colors
+const (
+	ansiReset  = "\033[0m"
+	ansiDim    = "\033[2m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+)
+
+// getTreeLineText returns the text of the tree line. With color, the kind and name
+// of the object follow its health indicator in the color of its health: a green check
+// when healthy, a yellow warning when progressing and a red cross when unhealthy.
+// The unknown objects are dimmed.
+func getTreeLineText(l TreeLine, color bool) string {
+	if !color {
+		return l.Prefix + l.Name
+	}
+
+	if l.ObjData.Unknown != "" {
+		return l.Prefix + ansiDim + l.Name + ansiReset
+	}
+
+	switch getObjHealth(l.ObjData) {
+	case HealthHealthy:
+		return l.Prefix + ansiGreen + "✔ " + l.Name + ansiReset
+	case HealthProgressing:
+		return l.Prefix + ansiYellow + "⚠ " + l.Name + ansiReset
+	case HealthUnhealthy:
+		return l.Prefix + ansiRed + "✘ " + l.Name + ansiReset
+	}
+
+	return l.Prefix + l.Name
+}
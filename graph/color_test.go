@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"bytes"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPrintTreeColor(t *testing.T) {
+	objData := ObjData{
+		Obj: unstructured.Unstructured{Object: map[string]interface{}{
+			"kind":     "Deployment",
+			"metadata": map[string]interface{}{"name": "api"},
+			"spec":     map[string]interface{}{"replicas": int64(2)},
+			"status":   map[string]interface{}{"readyReplicas": int64(1)},
+		}},
+		RelatedObjsData: []ObjData{
+			{
+				Obj: unstructured.Unstructured{Object: map[string]interface{}{
+					"kind":     "Pod",
+					"metadata": map[string]interface{}{"name": "api-1"},
+					"status":   map[string]interface{}{"phase": "Running"},
+				}},
+				Hierarchy:       "lower",
+				RelatedObjsData: []ObjData{},
+			},
+			{
+				Obj: unstructured.Unstructured{Object: map[string]interface{}{
+					"kind":     "Pod",
+					"metadata": map[string]interface{}{"name": "api-2"},
+					"status": map[string]interface{}{
+						"phase":             "Running",
+						"containerStatuses": []interface{}{map[string]interface{}{"state": map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}}}},
+					},
+				}},
+				Hierarchy:       "lower",
+				RelatedObjsData: []ObjData{},
+			},
+			{
+				Obj:             unstructured.Unstructured{Object: map[string]interface{}{"kind": "Service"}},
+				Hierarchy:       "upper",
+				Unknown:         "forbidden",
+				RelatedObjsData: []ObjData{},
+			},
+		},
+	}
+
+	tests := []struct {
+		Color             bool
+		ExpectedTreeGraph string
+	}{
+		{
+			true,
+			"\n\t┌── \033[2m[Service] unknown (forbidden)\033[0m\n" +
+				"\033[33m⚠ [Deployment] api\033[0m\n" +
+				"\t└── \033[32m✔ [Pod] api-1\033[0m\n" +
+				"\t└── \033[31m✘ [Pod] api-2\033[0m\n\n",
+		},
+		{
+			false,
+			"\n\t┌── [Service] unknown (forbidden)\n[Deployment] api\n\t└── [Pod] api-1\n\t└── [Pod] api-2\n\n",
+		},
+	}
+
+	for _, test := range tests {
+		o := &bytes.Buffer{}
+		p := NewPrinter([]ObjData{objData}, OutputTree, o)
+		p.Color = test.Color
+
+		err := p.Print()
+		if err != nil {
+			t.Fatalf("Graph could not be printed. Error: %q", err)
+		}
+
+		if o.String() != test.ExpectedTreeGraph {
+			t.Errorf("Returned graph was incorrect,\ngot:\n%q\nwant:\n%q", o.String(), test.ExpectedTreeGraph)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Cytoscape holds the elements of a graph in the Cytoscape.js JSON format,
+// read by cytoscape.js and imported by Cytoscape as a .cyjs file
+type Cytoscape struct {
+	Elements CytoscapeElements `json:"elements"`
+}
+
+// CytoscapeElements holds the nodes and edges of the graph
+type CytoscapeElements struct {
+	Nodes []CytoscapeNode `json:"nodes"`
+	Edges []CytoscapeEdge `json:"edges"`
+}
+
+// CytoscapeNode holds the attributes of a node
+type CytoscapeNode struct {
+	Data CytoscapeNodeData `json:"data"`
+}
+
+// CytoscapeNodeData holds the attributes of an object, label is shown by the Cytoscape styles
+type CytoscapeNodeData struct {
+	ID         string            `json:"id"`
+	Label      string            `json:"label"`
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Namespace  string            `json:"namespace"`
+	Name       string            `json:"name"`
+	UID        string            `json:"uid"`
+	Labels     map[string]string `json:"labels"`
+	Status     string            `json:"status"`
+	Unknown    string            `json:"unknown,omitempty"`
+}
+
+// CytoscapeEdge holds the attributes of an edge
+type CytoscapeEdge struct {
+	Data CytoscapeEdgeData `json:"data"`
+}
+
+// CytoscapeEdgeData holds the source and target nodes of an edge and their relation
+type CytoscapeEdgeData struct {
+	ID       string `json:"id"`
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Relation string `json:"relation"`
+}
+
+// createCytoscapeGraph returns a string holding the Cytoscape.js JSON of the objects data
+func createCytoscapeGraph(objsData []ObjData) (string, error) {
+	g := NewGraph(objsData)
+
+	c := Cytoscape{
+		Elements: CytoscapeElements{
+			Nodes: []CytoscapeNode{},
+			Edges: []CytoscapeEdge{},
+		},
+	}
+
+	for _, n := range g.Nodes {
+		l := n.Labels
+		if l == nil {
+			l = map[string]string{}
+		}
+
+		c.Elements.Nodes = append(c.Elements.Nodes, CytoscapeNode{
+			Data: CytoscapeNodeData{
+				ID:         n.ID,
+				Label:      getGraphNodeLabel(n),
+				APIVersion: n.APIVersion,
+				Kind:       n.Kind,
+				Namespace:  n.Namespace,
+				Name:       n.Name,
+				UID:        n.UID,
+				Labels:     l,
+				Status:     n.Status,
+				Unknown:    n.Unknown,
+			},
+		})
+	}
+
+	for i, e := range g.Edges {
+		c.Elements.Edges = append(c.Elements.Edges, CytoscapeEdge{
+			Data: CytoscapeEdgeData{
+				ID:       fmt.Sprintf("e%d", i),
+				Source:   e.Source,
+				Target:   e.Target,
+				Relation: e.Relation,
+			},
+		})
+	}
+
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(b) + "\n", nil
+}
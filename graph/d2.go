@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// createD2Graph returns a string holding the D2 diagram of the objects data.
+// The nodes have a shape per kind and are added to the container of their namespace,
+// the edges are labeled with their relation and the unknown objects have a dashed border.
+func createD2Graph(objsData []ObjData) string {
+	g := NewGraph(objsData)
+
+	// The edges refer to the nodes by their path in the containers
+	ids := map[string]string{}
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "direction: right")
+
+	for i, ns := range getGraphNamespaces(g) {
+		container := fmt.Sprintf("ns%d", i)
+		fmt.Fprintf(b, "%s: %s {\n", container, strconv.Quote(ns))
+
+		for _, n := range g.Nodes {
+			if n.Namespace == ns {
+				writeD2Node(b, "  ", ids[n.ID], n)
+				ids[n.ID] = container + "." + ids[n.ID]
+			}
+		}
+
+		fmt.Fprintln(b, "}")
+	}
+
+	for _, n := range g.Nodes {
+		if n.Namespace == "" {
+			writeD2Node(b, "", ids[n.ID], n)
+		}
+	}
+
+	for _, e := range g.Edges {
+		if e.Relation == "" {
+			fmt.Fprintf(b, "%s -> %s\n", ids[e.Source], ids[e.Target])
+		} else {
+			fmt.Fprintf(b, "%s -> %s: %s\n", ids[e.Source], ids[e.Target], e.Relation)
+		}
+	}
+
+	return b.String()
+}
+
+// writeD2Node writes the D2 shape of the graph node, the shape depends on the kind
+func writeD2Node(b *strings.Builder, indent, id string, n GraphNode) {
+	shape := "rectangle"
+
+	switch strings.ToLower(n.Kind) {
+	case "ingress":
+		shape = "cloud"
+	case "service":
+		shape = "hexagon"
+	case "deployment", "statefulset", "daemonset":
+		shape = "package"
+	case "replicaset":
+		shape = "parallelogram"
+	case "pod":
+		shape = "oval"
+	}
+
+	fmt.Fprintf(b, "%s%s: %s {\n", indent, id, strconv.Quote(getGraphNodeLabel(n)))
+	fmt.Fprintf(b, "%s  shape: %s\n", indent, shape)
+
+	if n.Unknown != "" {
+		fmt.Fprintf(b, "%s  style.stroke-dash: 5\n", indent)
+	}
+
+	fmt.Fprintf(b, "%s}\n", indent)
+}
@@ -0,0 +1,499 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/awalterschulze/gographviz"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Diff statuses of the nodes and edges
+const (
+	DiffAdded     = "added"
+	DiffRemoved   = "removed"
+	DiffChanged   = "changed"
+	DiffUnchanged = "unchanged"
+)
+
+// Diff holds the nodes and edges of two graphs with their diff status
+type Diff struct {
+	Nodes []DiffNode `json:"nodes"`
+	Edges []DiffEdge `json:"edges"`
+	trees []diffTree
+	// nodes holds the index of each node in Nodes, keyed by its ID
+	nodes map[string]int
+	// edges holds the index of each edge in Edges, keyed by its From and To IDs
+	edges map[DiffEdge]int
+}
+
+// DiffNode holds an object of the graphs. The namespace is empty when
+// the graphs of two namespaces are compared.
+type DiffNode struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Status    string `json:"status,omitempty"`
+}
+
+// DiffEdge holds a relation of the graphs from the upper to the lower object.
+// Relation holds the reason of the relation, e.g. ownerReference.
+type DiffEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Relation string `json:"relation,omitempty"`
+	Status   string `json:"status,omitempty"`
+}
+
+// diffTree holds a node of the tree of a root with the status of its relation
+type diffTree struct {
+	Node            DiffNode
+	Hierarchy       string
+	EdgeStatus      string
+	RelatedDiffTree []diffTree
+}
+
+// diffSide holds the objects and edges of one of the compared graphs, keyed by their diff ID
+type diffSide struct {
+	snapshot *Snapshot
+	objs     map[string]SnapshotObj
+	ids      map[string]string
+	roots    []string
+	// edges holds the edges of each root, keyed by their diff ID
+	edges map[string][]SnapshotEdge
+}
+
+// NewDiff returns the diff between the graphs of the before and after snapshots.
+// If ignoreNamespace is true, the objects are compared by kind and name, e.g. to
+// compare the graphs of the staging and prod namespaces.
+func NewDiff(before, after *Snapshot, ignoreNamespace bool) *Diff {
+	return newDiff(before, after, ignoreNamespace, false)
+}
+
+// newDiff returns the diff between the graphs of the before and after snapshots.
+// If compareStatus is true, a node is also changed when its status is different,
+// e.g. to print the changes of a watched graph.
+func newDiff(before, after *Snapshot, ignoreNamespace, compareStatus bool) *Diff {
+	b := newDiffSide(before, ignoreNamespace)
+	a := newDiffSide(after, ignoreNamespace)
+
+	d := &Diff{
+		Nodes: []DiffNode{},
+		Edges: []DiffEdge{},
+		trees: []diffTree{},
+		nodes: map[string]int{},
+		edges: map[DiffEdge]int{},
+	}
+
+	// Nodes
+	for _, side := range []*diffSide{a, b} {
+		for _, o := range side.snapshot.Objects {
+			id := side.ids[o.ID]
+			if d.getNode(id) != nil {
+				continue
+			}
+
+			d.nodes[id] = len(d.Nodes)
+			d.Nodes = append(d.Nodes, newDiffNode(id, o, getDiffNodeStatus(b.objs[id], a.objs[id], compareStatus), ignoreNamespace))
+		}
+	}
+
+	// Edges
+	afterEdges := a.getEdges()
+	beforeEdges := b.getEdges()
+
+	for _, e := range afterEdges {
+		status := DiffAdded
+		if containsDiffEdge(e, beforeEdges) {
+			status = DiffUnchanged
+		}
+		d.Edges = append(d.Edges, DiffEdge{From: e.From, To: e.To, Status: status})
+	}
+
+	for _, e := range beforeEdges {
+		if !containsDiffEdge(e, afterEdges) {
+			d.Edges = append(d.Edges, DiffEdge{From: e.From, To: e.To, Status: DiffRemoved})
+		}
+	}
+
+	f := NewFilter()
+	for i, e := range d.Edges {
+		d.Edges[i].Relation = f.GetRelation(d.getNode(e.From).Kind, d.getNode(e.To).Kind)
+		d.edges[DiffEdge{From: e.From, To: e.To}] = i
+	}
+
+	// Trees
+	roots := append([]string{}, a.roots...)
+	for _, r := range b.roots {
+		if !Contains(r, roots) {
+			roots = append(roots, r)
+		}
+	}
+
+	for _, r := range roots {
+		d.trees = append(d.trees, d.getDiffTree(a, b, r, r, "", "", map[string]bool{}))
+	}
+
+	return d
+}
+
+// newDiffSide returns the objects and edges of the snapshot keyed by their diff ID
+func newDiffSide(s *Snapshot, ignoreNamespace bool) *diffSide {
+	side := &diffSide{
+		snapshot: s,
+		objs:     map[string]SnapshotObj{},
+		ids:      map[string]string{},
+		roots:    []string{},
+		edges:    map[string][]SnapshotEdge{},
+	}
+
+	for _, o := range s.Objects {
+		id := getDiffID(o.ID, ignoreNamespace)
+		side.ids[o.ID] = id
+		side.objs[id] = o
+	}
+
+	for _, r := range s.Roots {
+		side.roots = append(side.roots, getDiffID(r, ignoreNamespace))
+	}
+
+	for _, e := range s.Edges {
+		root := getDiffID(e.Root, ignoreNamespace)
+		side.edges[root] = append(side.edges[root], SnapshotEdge{
+			Root:      root,
+			From:      getDiffID(e.From, ignoreNamespace),
+			To:        getDiffID(e.To, ignoreNamespace),
+			Hierarchy: e.Hierarchy,
+		})
+	}
+
+	return side
+}
+
+// getEdges returns the edges of all the roots from the upper to the lower object, without duplicates
+func (side *diffSide) getEdges() []DiffEdge {
+	edges := []DiffEdge{}
+
+	for _, r := range side.roots {
+		for _, e := range side.edges[r] {
+			de := DiffEdge{From: e.From, To: e.To}
+			if e.Hierarchy == "upper" {
+				de = DiffEdge{From: e.To, To: e.From}
+			}
+
+			if !containsDiffEdge(de, edges) {
+				edges = append(edges, de)
+			}
+		}
+	}
+
+	return edges
+}
+
+// getDiffTree returns the tree of the node in the graphs of the root. The related nodes of the
+// after graph come first, followed by the ones that were only related in the before graph.
+// The status of each relation is the status of its edge in Edges, so the tree agrees with
+// the JSON and DOT outputs when the roots of the graphs are different.
+func (d *Diff) getDiffTree(a, b *diffSide, root, id, hierarchy, edgeStatus string, visited map[string]bool) diffTree {
+	visited[id] = true
+
+	t := diffTree{
+		Node:       *d.getNode(id),
+		Hierarchy:  hierarchy,
+		EdgeStatus: edgeStatus,
+	}
+
+	for _, e := range a.edges[root] {
+		if e.From != id || visited[e.To] {
+			continue
+		}
+
+		t.RelatedDiffTree = append(t.RelatedDiffTree, d.getDiffTree(a, b, root, e.To, e.Hierarchy, d.getEdgeStatus(e), visited))
+	}
+
+	for _, e := range b.edges[root] {
+		if e.From != id || visited[e.To] || containsSnapshotEdge(e, a.edges[root]) {
+			continue
+		}
+
+		t.RelatedDiffTree = append(t.RelatedDiffTree, d.getDiffTree(a, b, root, e.To, e.Hierarchy, d.getEdgeStatus(e), visited))
+	}
+
+	return t
+}
+
+// getEdgeStatus returns the status of the edge in Edges, where the edges go from the upper to the lower object
+func (d *Diff) getEdgeStatus(e SnapshotEdge) string {
+	key := DiffEdge{From: e.From, To: e.To}
+	if e.Hierarchy == "upper" {
+		key = DiffEdge{From: e.To, To: e.From}
+	}
+
+	return d.Edges[d.edges[key]].Status
+}
+
+// getNode returns the node with the ID or nil
+func (d *Diff) getNode(id string) *DiffNode {
+	i, found := d.nodes[id]
+	if !found {
+		return nil
+	}
+
+	return &d.Nodes[i]
+}
+
+// HasChanges returns true if some node or edge was added, removed or changed
+func (d *Diff) HasChanges() bool {
+	for _, n := range d.Nodes {
+		if n.Status != DiffUnchanged {
+			return true
+		}
+	}
+
+	for _, e := range d.Edges {
+		if e.Status != DiffUnchanged {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PrintTree prints the trees of the roots of both graphs. The nodes and
+// relations that were added, removed or changed are marked with their status.
+func (d *Diff) PrintTree(out io.Writer) {
+	g := ""
+
+	for _, t := range d.trees {
+		g = g + fmt.Sprintf("\n%s\n\n", createDiffTreeGraph(t, "", ""))
+	}
+
+	fmt.Fprint(out, g)
+}
+
+// createDiffTreeGraph returns a string holding the diff tree graph
+func createDiffTreeGraph(t diffTree, graph, format string) string {
+	line := fmt.Sprintf("[%s] %s%s", t.Node.Kind, t.Node.Name, getDiffStatusSuffix(t))
+
+	if graph == "" {
+		graph = line
+	} else if t.Hierarchy == "upper" {
+		graph = fmt.Sprintf("%s┌── %s", format, line)
+	} else if t.Hierarchy == "lower" {
+		graph = fmt.Sprintf("%s└── %s", format, line)
+	}
+
+	format = format + "\t"
+
+	for _, r := range t.RelatedDiffTree {
+		relatedGraph := createDiffTreeGraph(r, graph, format)
+
+		if r.Hierarchy == "upper" {
+			graph = relatedGraph + "\n" + graph
+		} else {
+			graph = graph + "\n" + relatedGraph
+		}
+	}
+
+	return graph
+}
+
+// getDiffStatusSuffix returns the status of the node, or of its relation
+// when only the relation was added or removed
+func getDiffStatusSuffix(t diffTree) string {
+	switch {
+	case t.Node.Status == DiffAdded || t.Node.Status == DiffRemoved:
+		return " (" + t.Node.Status + ")"
+	case t.EdgeStatus == DiffAdded || t.EdgeStatus == DiffRemoved:
+		status := "relation " + t.EdgeStatus
+		if t.Node.Status == DiffChanged {
+			status = DiffChanged + ", " + status
+		}
+		return " (" + status + ")"
+	case t.Node.Status == DiffChanged:
+		return " (" + DiffChanged + ")"
+	}
+
+	return ""
+}
+
+// PrintDot prints a dot graph with the nodes and edges colored by their status:
+// green when added, red when removed and orange when changed
+func (d *Diff) PrintDot(out io.Writer) error {
+	g := gographviz.NewGraph()
+
+	err := g.SetName("W")
+	if err != nil {
+		return err
+	}
+
+	err = g.SetDir(true)
+	if err != nil {
+		return err
+	}
+
+	err = g.SetStrict(true)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range d.Nodes {
+		attrs := map[string]string{"label": quoteDotID(n.Kind + ": " + n.Name)}
+		if color := getDiffColor(n.Status); color != "" {
+			attrs["color"] = color
+			attrs["fontcolor"] = color
+		}
+
+		err := g.AddNode("W", quoteDotID(n.ID), attrs)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, e := range d.Edges {
+		attrs := map[string]string{}
+		if color := getDiffColor(e.Status); color != "" {
+			attrs["color"] = color
+		}
+		if e.Status == DiffRemoved {
+			attrs["style"] = "dashed"
+		}
+
+		err := g.AddEdge(quoteDotID(e.From), quoteDotID(e.To), true, attrs)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprint(out, g.String())
+
+	return nil
+}
+
+// getDiffColor returns the dot color of the status
+func getDiffColor(status string) string {
+	switch status {
+	case DiffAdded:
+		return "green"
+	case DiffRemoved:
+		return "red"
+	case DiffChanged:
+		return "orange"
+	}
+
+	return ""
+}
+
+// PrintJSON prints the nodes and edges with their status as JSON
+func (d *Diff) PrintJSON(out io.Writer) error {
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, string(b))
+
+	return nil
+}
+
+// newDiffNode returns the node of the snapshot object
+func newDiffNode(id string, o SnapshotObj, status string, ignoreNamespace bool) DiffNode {
+	obj := unstructured.Unstructured{Object: o.Object}
+
+	n := DiffNode{
+		ID:     id,
+		Kind:   obj.GetKind(),
+		Name:   obj.GetName(),
+		Status: status,
+	}
+
+	if o.Unknown != "" {
+		n.Name = getObjName(ObjData{Unknown: o.Unknown})
+	}
+
+	if !ignoreNamespace {
+		n.Namespace = obj.GetNamespace()
+	}
+
+	return n
+}
+
+// getDiffNodeStatus returns the status of a node from its before and after objects.
+// A node is changed when its spec or labels are different, or its status when
+// compareStatus is true.
+func getDiffNodeStatus(before, after SnapshotObj, compareStatus bool) string {
+	switch {
+	case before.ID == "":
+		return DiffAdded
+	case after.ID == "":
+		return DiffRemoved
+	}
+
+	b := unstructured.Unstructured{Object: before.Object}
+	a := unstructured.Unstructured{Object: after.Object}
+
+	if !reflect.DeepEqual(b.GetLabels(), a.GetLabels()) || !reflect.DeepEqual(before.Object["spec"], after.Object["spec"]) {
+		return DiffChanged
+	}
+
+	if compareStatus && getDiffObjStatus(before) != getDiffObjStatus(after) {
+		return DiffChanged
+	}
+
+	return DiffUnchanged
+}
+
+// getDiffObjStatus returns the status fields of the object that are compared: the pod
+// phase, ready containers and restarts, and the ready replicas of the controllers
+func getDiffObjStatus(o SnapshotObj) string {
+	objData := ObjData{Obj: unstructured.Unstructured{Object: o.Object}, Unknown: o.Unknown}
+	status := getObjStatus(objData)
+
+	if _, found := o.Object["status"]; found && o.Unknown == "" && strings.ToLower(objData.Obj.GetKind()) == "pod" {
+		ready, restarts := getPodContainers(objData.Obj)
+		status = fmt.Sprintf("%s %s %s", status, ready, restarts)
+	}
+
+	return status
+}
+
+// getDiffID returns the ID used to compare the objects of a snapshot ID. The
+// namespace is removed from the ID when the namespace is ignored.
+func getDiffID(id string, ignoreNamespace bool) string {
+	if !ignoreNamespace {
+		return id
+	}
+
+	parts := strings.SplitN(id, "/", 3)
+	if len(parts) != 3 {
+		return id
+	}
+
+	return parts[0] + "/" + parts[2]
+}
+
+// containsDiffEdge returns true if the edge is in the list of edges
+func containsDiffEdge(edge DiffEdge, edges []DiffEdge) bool {
+	for _, e := range edges {
+		if e.From == edge.From && e.To == edge.To {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsSnapshotEdge returns true if the edge is in the list of edges
+func containsSnapshotEdge(edge SnapshotEdge, edges []SnapshotEdge) bool {
+	for _, e := range edges {
+		if e.From == edge.From && e.To == edge.To && e.Hierarchy == edge.Hierarchy {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,170 @@
+package graph
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// newTestSnapshot returns the snapshot of the graph of the service service-foo
+func newTestSnapshot(t *testing.T, s Source, namespace string) *Snapshot {
+	b := NewBuilder(s, &bytes.Buffer{}, OutputTree, []string{namespace}, []Root{{Kind: "service", Name: "service-foo"}})
+
+	err := b.Collect()
+	if err != nil {
+		t.Fatalf("Graph could not be created. Error: %q", err)
+	}
+
+	return NewSnapshot(b.ObjsData, b.Warnings)
+}
+
+func TestNewDiff(t *testing.T) {
+	before := newTestSnapshot(t, NewFakeSource("default"), "default")
+
+	// pod-foo-1 loses the service and pod-foo-2 gets it
+	s := NewFakeSource("default")
+	for i, o := range s.Objs {
+		switch o.GetName() {
+		case "pod-foo-1":
+			s.Objs[i].SetLabels(map[string]string{"app": "foo", "version": "v0"})
+		case "pod-foo-2":
+			s.Objs[i].SetLabels(map[string]string{"app": "foo", "version": "v1"})
+		}
+	}
+
+	after := newTestSnapshot(t, s, "default")
+
+	d := NewDiff(before, after, false)
+
+	o := &bytes.Buffer{}
+	d.PrintTree(o)
+
+	expected := "\n[Service] service-foo\n\t\t┌── [Statefulset] statefulset-foo-1 (relation added)\n\t└── [Pod] pod-foo-2 (added)\n\t└── [Pod] pod-foo-1 (removed)\n\n"
+
+	if o.String() != expected {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), expected)
+	}
+
+	expectedEdges := []DiffEdge{
+		{From: "service/default/service-foo", To: "pod/default/pod-foo-2", Relation: "labelSelector", Status: DiffAdded},
+		{From: "statefulset.apps/default/statefulset-foo-1", To: "pod/default/pod-foo-2", Relation: "ownerReference", Status: DiffAdded},
+		{From: "service/default/service-foo", To: "pod/default/pod-foo-1", Relation: "labelSelector", Status: DiffRemoved},
+		{From: "statefulset.apps/default/statefulset-foo-1", To: "pod/default/pod-foo-1", Relation: "ownerReference", Status: DiffRemoved},
+	}
+
+	if !reflect.DeepEqual(d.Edges, expectedEdges) {
+		t.Errorf("Returned edges were incorrect, got: %v want: %v", d.Edges, expectedEdges)
+	}
+
+	if !d.HasChanges() {
+		t.Errorf("Returned result was incorrect, got: no changes want: changes")
+	}
+}
+
+func TestNewDiffRoots(t *testing.T) {
+	before := newTestSnapshot(t, NewFakeSource("default"), "default")
+
+	b := NewBuilder(NewFakeSource("default"), &bytes.Buffer{}, OutputTree, []string{"default"}, []Root{{Kind: "pod", Name: "pod-foo-1"}})
+
+	err := b.Collect()
+	if err != nil {
+		t.Fatalf("Graph could not be created. Error: %q", err)
+	}
+
+	after := NewSnapshot(b.ObjsData, b.Warnings)
+
+	d := NewDiff(before, after, false)
+
+	o := &bytes.Buffer{}
+	d.PrintTree(o)
+
+	// The relations are unchanged, only the roots of the graphs are different
+	expected := "\n\t┌── [Statefulset] statefulset-foo-1\n\t┌── [Service] service-foo\n[Pod] pod-foo-1\n\n\n[Service] service-foo\n\t\t┌── [Statefulset] statefulset-foo-1\n\t└── [Pod] pod-foo-1\n\n"
+
+	if o.String() != expected {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), expected)
+	}
+
+	for _, e := range d.Edges {
+		if e.Status != DiffUnchanged {
+			t.Errorf("Returned edge status was incorrect for %s -> %s, got: %v want: %v", e.From, e.To, e.Status, DiffUnchanged)
+		}
+	}
+}
+
+func TestNewDiffNamespaces(t *testing.T) {
+	s := NewFakeSource("staging", "prod")
+
+	d := NewDiff(newTestSnapshot(t, s, "staging"), newTestSnapshot(t, s, "prod"), true)
+
+	if d.HasChanges() {
+		t.Errorf("Returned result was incorrect, got: %v want: no changes", d.Nodes)
+	}
+
+	d = NewDiff(newTestSnapshot(t, s, "staging"), newTestSnapshot(t, s, "prod"), false)
+
+	if !d.HasChanges() {
+		t.Errorf("Returned result was incorrect, got: no changes want: changes")
+	}
+}
+
+func TestGetDiffNodeStatus(t *testing.T) {
+	spec := map[string]interface{}{"replicas": int64(1)}
+
+	pod := func(phase string, restarts int64) map[string]interface{} {
+		return map[string]interface{}{
+			"kind": "Pod",
+			"spec": map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "foo"}}},
+			"status": map[string]interface{}{
+				"phase":             phase,
+				"containerStatuses": []interface{}{map[string]interface{}{"ready": true, "restartCount": restarts}},
+			},
+		}
+	}
+
+	tests := []struct {
+		Before        SnapshotObj
+		After         SnapshotObj
+		CompareStatus bool
+		Expected      string
+	}{
+		{SnapshotObj{}, SnapshotObj{ID: "a"}, false, DiffAdded},
+		{SnapshotObj{ID: "a"}, SnapshotObj{}, false, DiffRemoved},
+		{
+			SnapshotObj{ID: "a", Object: map[string]interface{}{"spec": spec}},
+			SnapshotObj{ID: "a", Object: map[string]interface{}{"spec": spec, "status": map[string]interface{}{"ready": true}}},
+			false,
+			DiffUnchanged,
+		},
+		{
+			SnapshotObj{ID: "a", Object: map[string]interface{}{"spec": spec}},
+			SnapshotObj{ID: "a", Object: map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(2)}}},
+			false,
+			DiffChanged,
+		},
+		{
+			SnapshotObj{ID: "a", Object: map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "foo"}}}},
+			SnapshotObj{ID: "a", Object: map[string]interface{}{}},
+			false,
+			DiffChanged,
+		},
+		// Only the status of the pod changed
+		{SnapshotObj{ID: "a", Object: pod("Running", 0)}, SnapshotObj{ID: "a", Object: pod("Running", 1)}, false, DiffUnchanged},
+		{SnapshotObj{ID: "a", Object: pod("Running", 0)}, SnapshotObj{ID: "a", Object: pod("Running", 1)}, true, DiffChanged},
+		{SnapshotObj{ID: "a", Object: pod("Pending", 0)}, SnapshotObj{ID: "a", Object: pod("Running", 0)}, true, DiffChanged},
+		{SnapshotObj{ID: "a", Object: pod("Running", 0)}, SnapshotObj{ID: "a", Object: pod("Running", 0)}, true, DiffUnchanged},
+		{
+			SnapshotObj{ID: "a", Object: map[string]interface{}{"kind": "ReplicaSet", "status": map[string]interface{}{"readyReplicas": int64(0)}}},
+			SnapshotObj{ID: "a", Object: map[string]interface{}{"kind": "ReplicaSet", "status": map[string]interface{}{"readyReplicas": int64(1)}}},
+			true,
+			DiffChanged,
+		},
+	}
+
+	for _, test := range tests {
+		s := getDiffNodeStatus(test.Before, test.After, test.CompareStatus)
+		if s != test.Expected {
+			t.Errorf("Returned result was incorrect, got: %s want: %s", s, test.Expected)
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package graph
 
 import (
+	"fmt"
 	"strings"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -62,22 +66,186 @@ func (f *Filter) FilterObj(obj unstructured.Unstructured, relatedObj unstructure
 	return false
 }
 
-// getBackendNames returns the backend services configured in an ingress
+// GetRelation returns the reason why the objects of two kinds are related: an owner
+// reference, the label selector of a service, an ingress backend or the external name
+// of an ExternalName service
+func (f *Filter) GetRelation(kind, relatedKind string) string {
+	switch strings.ToLower(kind) + "/" + strings.ToLower(relatedKind) {
+	case "pod/service", "service/pod":
+		return "labelSelector"
+	case "service/ingress", "ingress/service":
+		return "ingressBackend"
+	case "service/service":
+		return "externalName"
+	case "pod/replicaset", "pod/statefulset", "pod/daemonset",
+		"replicaset/pod", "statefulset/pod", "daemonset/pod",
+		"replicaset/deployment", "deployment/replicaset":
+		return "ownerReference"
+	}
+
+	return ""
+}
+
+// RequiresSpec returns true if the relations of a kind inspect its spec fields,
+// so the full objects are needed instead of their metadata only.
+// Services are related by their selector and ingresses by their backends.
+func (f *Filter) RequiresSpec(kind string) bool {
+	switch kind {
+	case "service", "ingress":
+		return true
+	}
+
+	return false
+}
+
+// RequiresParentSpec returns true if the related objects of a kind are listed with the
+// selector found in the spec of the parent kind, e.g. the pods of a replicaset.
+// Without the spec, all the objects of the related kind in the namespace are listed.
+func (f *Filter) RequiresParentSpec(kind, relatedKind string) bool {
+	switch kind + "/" + relatedKind {
+	case "service/pod", "ingress/service",
+		"deployment/replicaset", "replicaset/pod", "statefulset/pod", "daemonset/pod":
+		return true
+	}
+
+	return false
+}
+
+// GetListOptions returns the list options with the label and field selectors that let
+// the API server filter the related objects of a kind. FilterObj must still be used
+// on the listed objects. It returns false if no object of the kind can be related.
+func (f *Filter) GetListOptions(obj unstructured.Unstructured, relatedKind string) (v1.ListOptions, bool) {
+	switch strings.ToLower(obj.GetKind()) {
+	case "pod":
+		switch relatedKind {
+		case "replicaset", "statefulset", "daemonset":
+			return getOwnerListOptions(obj.GetOwnerReferences(), relatedKind)
+		}
+	case "service":
+		switch relatedKind {
+		case "pod":
+			selector := getSelector(obj)
+			if selector == nil {
+				return v1.ListOptions{}, false
+			}
+
+			return v1.ListOptions{LabelSelector: getLabelSelector(selector)}, true
+		}
+	case "ingress":
+		switch relatedKind {
+		case "service":
+			return getNameListOptions(getBackendNames(obj))
+		}
+	case "replicaset":
+		switch relatedKind {
+		case "deployment":
+			return getOwnerListOptions(obj.GetOwnerReferences(), relatedKind)
+		case "pod":
+			return v1.ListOptions{LabelSelector: getOwnerLabelSelector(obj)}, true
+		}
+	case "deployment", "statefulset", "daemonset":
+		switch relatedKind {
+		case "replicaset", "pod":
+			return v1.ListOptions{LabelSelector: getOwnerLabelSelector(obj)}, true
+		}
+	}
+
+	return v1.ListOptions{}, true
+}
+
+// getOwnerListOptions returns the list options to get the owners of a kind
+// found in an owner reference list
+func getOwnerListOptions(ownerReferences []v1.OwnerReference, ownerKind string) (v1.ListOptions, bool) {
+	names := []string{}
+
+	for _, r := range ownerReferences {
+		if strings.ToLower(r.Kind) == ownerKind {
+			names = append(names, r.Name)
+		}
+	}
+
+	return getNameListOptions(names)
+}
+
+// getNameListOptions returns the list options to get the objects by name.
+// The field selector is only set for a single name because the API server
+// does not support the OR operator.
+func getNameListOptions(names []string) (v1.ListOptions, bool) {
+	unique := []string{}
+
+	for _, n := range names {
+		if !Contains(n, unique) {
+			unique = append(unique, n)
+		}
+	}
+
+	switch len(unique) {
+	case 0:
+		return v1.ListOptions{}, false
+	case 1:
+		return v1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", unique[0]).String()}, true
+	}
+
+	return v1.ListOptions{}, true
+}
+
+// getLabelSelector returns the label selector string of a service selector
+func getLabelSelector(selector map[string]interface{}) string {
+	l := labels.Set{}
+
+	for key, value := range selector {
+		l[key] = fmt.Sprint(value)
+	}
+
+	return l.AsSelector().String()
+}
+
+// getOwnerLabelSelector returns the label selector string of a deployment, replicaset,
+// statefulset or daemonset. The objects they own always match this selector.
+// An empty string is returned if the selector is not found or not valid.
+func getOwnerLabelSelector(ownerObj unstructured.Unstructured) string {
+	s, found, err := unstructured.NestedMap(ownerObj.Object, "spec", "selector")
+	if err != nil || !found {
+		return ""
+	}
+
+	labelSelector := &v1.LabelSelector{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(s, labelSelector)
+	if err != nil {
+		return ""
+	}
+
+	selector, err := v1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return ""
+	}
+
+	return selector.String()
+}
+
+// getBackendNames returns the backend services configured in an ingress.
+// Both the v1beta1 serviceName and the v1 service.name backends are supported.
 func getBackendNames(ingressObj unstructured.Unstructured) []string {
 	backendServiceNames := []string{}
-	rules := ingressObj.Object["spec"].(map[string]interface{})["rules"]
-
-	if rules != nil {
-		for _, r := range rules.([]interface{}) {
-			http := r.(map[string]interface{})["http"]
-			if http != nil {
-				paths := http.(map[string]interface{})["paths"]
-				if paths != nil {
-					for _, p := range paths.([]interface{}) {
-						backend := p.(map[string]interface{})["backend"]
-						backendServiceNames = append(backendServiceNames, backend.(map[string]interface{})["serviceName"].(string))
-					}
-				}
+	rules, _, _ := unstructured.NestedSlice(ingressObj.Object, "spec", "rules")
+
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		paths, _, _ := unstructured.NestedSlice(rule, "http", "paths")
+		for _, p := range paths {
+			path, ok := p.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if name, found, _ := unstructured.NestedString(path, "backend", "serviceName"); found {
+				backendServiceNames = append(backendServiceNames, name)
+			} else if name, found, _ := unstructured.NestedString(path, "backend", "service", "name"); found {
+				backendServiceNames = append(backendServiceNames, name)
 			}
 		}
 	}
@@ -85,15 +253,37 @@ func getBackendNames(ingressObj unstructured.Unstructured) []string {
 	return backendServiceNames
 }
 
-// getSelector returns the label selector from a service
-func getSelector(serviceObj unstructured.Unstructured) map[string]interface{} {
-	s := serviceObj.Object["spec"].(map[string]interface{})["selector"]
+// getExternalNameService returns the name and namespace of the service of the cluster
+// an ExternalName service points to, e.g. db.data.svc.cluster.local. It returns false
+// when the service is not an ExternalName service or its external name is not the
+// DNS name of a service of the cluster.
+func getExternalNameService(serviceObj unstructured.Unstructured) (string, string, bool) {
+	if strings.ToLower(serviceObj.GetKind()) != "service" {
+		return "", "", false
+	}
 
-	if s != nil {
-		return s.(map[string]interface{})
+	serviceType, _, _ := unstructured.NestedString(serviceObj.Object, "spec", "type")
+	externalName, _, _ := unstructured.NestedString(serviceObj.Object, "spec", "externalName")
+
+	// <service>.<namespace>.svc, followed by the cluster domain
+	parts := strings.Split(strings.TrimSuffix(externalName, "."), ".")
+	if serviceType != "ExternalName" || len(parts) < 3 || parts[2] != "svc" || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+// getSelector returns the label selector from a service, nil if the service has no spec
+// or selector, or the selector is empty or not valid. Like the API server, an empty
+// selector selects no pod.
+func getSelector(serviceObj unstructured.Unstructured) map[string]interface{} {
+	s, found, err := unstructured.NestedMap(serviceObj.Object, "spec", "selector")
+	if err != nil || !found || len(s) == 0 {
+		return nil
 	}
 
-	return nil
+	return s
 }
 
 // filterByServiceName returns true if a service name is found in
@@ -182,3 +182,225 @@ func TestFilter(t *testing.T) {
 		}
 	}
 }
+
+func TestGetListOptions(t *testing.T) {
+
+	tests := []struct {
+		Obj           unstructured.Unstructured
+		RelatedKind   string
+		LabelSelector string
+		FieldSelector string
+		Expected      bool
+	}{
+		{
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"kind": "Service",
+					"spec": map[string]interface{}{
+						"selector": map[string]interface{}{
+							"app":     "foo",
+							"version": "v1",
+						},
+					},
+				},
+			},
+			"pod",
+			"app=foo,version=v1",
+			"",
+			true,
+		},
+		{
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"kind": "Service",
+					"spec": map[string]interface{}{},
+				},
+			},
+			"pod",
+			"",
+			"",
+			false,
+		},
+		{
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"kind": "Service",
+				},
+			},
+			"pod",
+			"",
+			"",
+			false,
+		},
+		{
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"kind": "Service",
+					"spec": map[string]interface{}{"selector": map[string]interface{}{}},
+				},
+			},
+			"pod",
+			"",
+			"",
+			false,
+		},
+		{
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"kind": "Pod",
+					"metadata": map[string]interface{}{
+						"ownerReferences": []interface{}{
+							map[string]interface{}{
+								"kind": "ReplicaSet",
+								"name": "replicaset-foo",
+								"uid":  "1d1fcfc1-6f23-4578-9b70-8361a733ab26",
+							},
+						},
+					},
+				},
+			},
+			"replicaset",
+			"",
+			"metadata.name=replicaset-foo",
+			true,
+		},
+		{
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"kind": "Pod",
+					"metadata": map[string]interface{}{
+						"ownerReferences": []interface{}{
+							map[string]interface{}{
+								"kind": "ReplicaSet",
+								"name": "replicaset-foo",
+								"uid":  "1d1fcfc1-6f23-4578-9b70-8361a733ab26",
+							},
+						},
+					},
+				},
+			},
+			"statefulset",
+			"",
+			"",
+			false,
+		},
+		{
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"kind": "Pod",
+				},
+			},
+			"service",
+			"",
+			"",
+			true,
+		},
+		{
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"kind": "Ingress",
+					"spec": map[string]interface{}{
+						"rules": []interface{}{
+							map[string]interface{}{
+								"http": map[string]interface{}{
+									"paths": []interface{}{
+										map[string]interface{}{
+											"backend": map[string]interface{}{
+												"serviceName": "service-foo",
+											},
+										},
+										map[string]interface{}{
+											"backend": map[string]interface{}{
+												"serviceName": "service-foo",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"service",
+			"",
+			"metadata.name=service-foo",
+			true,
+		},
+		{
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"kind": "Deployment",
+					"spec": map[string]interface{}{
+						"selector": map[string]interface{}{
+							"matchLabels": map[string]interface{}{
+								"app": "foo",
+							},
+						},
+					},
+				},
+			},
+			"replicaset",
+			"app=foo",
+			"",
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		f := NewFilter()
+		opts, ok := f.GetListOptions(test.Obj, test.RelatedKind)
+
+		if ok != test.Expected || opts.LabelSelector != test.LabelSelector || opts.FieldSelector != test.FieldSelector {
+			t.Errorf("Returned result was incorrect, got: %t %q %q want: %t %q %q", ok, opts.LabelSelector, opts.FieldSelector, test.Expected, test.LabelSelector, test.FieldSelector)
+		}
+	}
+}
+
+func TestGetRelation(t *testing.T) {
+
+	tests := []struct {
+		Kind        string
+		RelatedKind string
+		Expected    string
+	}{
+		{"Service", "Pod", "labelSelector"},
+		{"Ingress", "Service", "ingressBackend"},
+		{"Deployment", "ReplicaSet", "ownerReference"},
+		{"Pod", "StatefulSet", "ownerReference"},
+		{"Service", "Service", "externalName"},
+		{"Ingress", "Pod", ""},
+	}
+
+	f := NewFilter()
+
+	for _, test := range tests {
+		r := f.GetRelation(test.Kind, test.RelatedKind)
+		if r != test.Expected {
+			t.Errorf("Returned result was incorrect for %s and %s, got: %s want: %s", test.Kind, test.RelatedKind, r, test.Expected)
+		}
+	}
+}
+
+func TestGetExternalNameService(t *testing.T) {
+
+	tests := []struct {
+		Spec              map[string]interface{}
+		ExpectedName      string
+		ExpectedNamespace string
+		ExpectedOK        bool
+	}{
+		{map[string]interface{}{"type": "ExternalName", "externalName": "db.data.svc.cluster.local"}, "db", "data", true},
+		{map[string]interface{}{"type": "ExternalName", "externalName": "db.data.svc."}, "db", "data", true},
+		{map[string]interface{}{"type": "ExternalName", "externalName": "smtp.example.com"}, "", "", false},
+		{map[string]interface{}{"type": "ClusterIP", "externalName": "db.data.svc.cluster.local"}, "", "", false},
+	}
+
+	for _, test := range tests {
+		o := unstructured.Unstructured{Object: map[string]interface{}{"kind": "Service", "spec": test.Spec}}
+
+		name, namespace, ok := getExternalNameService(o)
+		if name != test.ExpectedName || namespace != test.ExpectedNamespace || ok != test.ExpectedOK {
+			t.Errorf("Returned result was incorrect for %v, got: %s %s %t want: %s %s %t", test.Spec, name, namespace, ok, test.ExpectedName, test.ExpectedNamespace, test.ExpectedOK)
+		}
+	}
+}
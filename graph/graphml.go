@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"encoding/xml"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// graphMLNamespace is the XML namespace of the GraphML documents
+const graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+// GraphML holds the GraphML document of a graph, read by yEd, Gephi and Cytoscape
+type GraphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []GraphMLKey `xml:"key"`
+	Graph   GraphMLGraph `xml:"graph"`
+}
+
+// GraphMLKey declares an attribute of the nodes or edges
+type GraphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+// GraphMLGraph holds the nodes and edges of the GraphML document
+type GraphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []GraphMLNode `xml:"node"`
+	Edges       []GraphMLEdge `xml:"edge"`
+}
+
+// GraphMLNode holds a node and its attributes
+type GraphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []GraphMLData `xml:"data"`
+}
+
+// GraphMLEdge holds an edge and its attributes
+type GraphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []GraphMLData `xml:"data"`
+}
+
+// GraphMLData holds the value of an attribute declared by a key
+type GraphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphMLNodeKeys holds the attributes of the nodes, in the order they are written
+var graphMLNodeKeys = []string{"label", "apiVersion", "kind", "namespace", "name", "uid", "labels", "status", "unknown"}
+
+// createGraphMLGraph returns a string holding the GraphML document of the objects data.
+// The labels of the objects are written as a selector, e.g. app=foo,version=v1.
+func createGraphMLGraph(objsData []ObjData) (string, error) {
+	g := NewGraph(objsData)
+
+	doc := GraphML{
+		XMLNS: graphMLNamespace,
+		Graph: GraphMLGraph{
+			ID:          "kubegraph",
+			EdgeDefault: "directed",
+			Nodes:       []GraphMLNode{},
+			Edges:       []GraphMLEdge{},
+		},
+	}
+
+	for _, k := range graphMLNodeKeys {
+		doc.Keys = append(doc.Keys, GraphMLKey{ID: k, For: "node", AttrName: k, AttrType: "string"})
+	}
+	doc.Keys = append(doc.Keys, GraphMLKey{ID: "relation", For: "edge", AttrName: "relation", AttrType: "string"})
+
+	for _, n := range g.Nodes {
+		values := map[string]string{
+			"label":      getGraphNodeLabel(n),
+			"apiVersion": n.APIVersion,
+			"kind":       n.Kind,
+			"namespace":  n.Namespace,
+			"name":       n.Name,
+			"uid":        n.UID,
+			"labels":     labels.Set(n.Labels).String(),
+			"status":     n.Status,
+			"unknown":    n.Unknown,
+		}
+
+		node := GraphMLNode{ID: n.ID}
+
+		for _, k := range graphMLNodeKeys {
+			if values[k] != "" {
+				node.Data = append(node.Data, GraphMLData{Key: k, Value: values[k]})
+			}
+		}
+
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+
+	for _, e := range g.Edges {
+		edge := GraphMLEdge{Source: e.Source, Target: e.Target}
+
+		if e.Relation != "" {
+			edge.Data = append(edge.Data, GraphMLData{Key: "relation", Value: e.Relation})
+		}
+
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return xml.Header + string(b) + "\n", nil
+}
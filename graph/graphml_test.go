@@ -0,0 +1,79 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestPrintGraphML(t *testing.T) {
+	s := NewFakeSource("default")
+	s.Forbidden = []string{"ingresses"}
+
+	o := &bytes.Buffer{}
+
+	b := NewBuilder(s, o, OutputGraphML, []string{"default"}, []Root{{Kind: "pod", Name: "pod-foo-1"}})
+
+	err := b.Build()
+	if err != nil {
+		t.Fatalf("Graph could not be created. Error: %q", err)
+	}
+
+	doc := &GraphML{}
+
+	err = xml.Unmarshal(o.Bytes(), doc)
+	if err != nil {
+		t.Fatalf("GraphML could not be read. Error: %q", err)
+	}
+
+	if len(doc.Keys) != len(graphMLNodeKeys)+1 {
+		t.Errorf("Returned keys were incorrect, got: %d, want: %d", len(doc.Keys), len(graphMLNodeKeys)+1)
+	}
+
+	expectedNodes := []GraphMLNode{
+		{ID: "pod/default/pod-foo-1", Data: []GraphMLData{
+			{Key: "label", Value: "Pod: pod-foo-1"},
+			{Key: "apiVersion", Value: "v1"},
+			{Key: "kind", Value: "Pod"},
+			{Key: "namespace", Value: "default"},
+			{Key: "name", Value: "pod-foo-1"},
+			{Key: "labels", Value: "app=foo,version=v1"},
+		}},
+		{ID: "service/default/service-foo", Data: []GraphMLData{
+			{Key: "label", Value: "Service: service-foo"},
+			{Key: "apiVersion", Value: "v1"},
+			{Key: "kind", Value: "Service"},
+			{Key: "namespace", Value: "default"},
+			{Key: "name", Value: "service-foo"},
+		}},
+		{ID: "ingress/default/(forbidden)", Data: []GraphMLData{
+			{Key: "label", Value: "Ingress: unknown (forbidden)"},
+			{Key: "kind", Value: "Ingress"},
+			{Key: "namespace", Value: "default"},
+			{Key: "unknown", Value: "forbidden"},
+		}},
+		{ID: "statefulset.apps/default/statefulset-foo-1", Data: []GraphMLData{
+			{Key: "label", Value: "Statefulset: statefulset-foo-1"},
+			{Key: "apiVersion", Value: "apps/v1"},
+			{Key: "kind", Value: "Statefulset"},
+			{Key: "namespace", Value: "default"},
+			{Key: "name", Value: "statefulset-foo-1"},
+			{Key: "uid", Value: "1d1fcfc1-6f23-4578-9b70-8361a733ab26"},
+		}},
+	}
+
+	if !reflect.DeepEqual(doc.Graph.Nodes, expectedNodes) {
+		t.Errorf("Returned nodes were incorrect,\ngot:\n%+v\nwant:\n%+v", doc.Graph.Nodes, expectedNodes)
+	}
+
+	expectedEdges := []GraphMLEdge{
+		{Source: "service/default/service-foo", Target: "pod/default/pod-foo-1", Data: []GraphMLData{{Key: "relation", Value: "labelSelector"}}},
+		{Source: "ingress/default/(forbidden)", Target: "service/default/service-foo", Data: []GraphMLData{{Key: "relation", Value: "ingressBackend"}}},
+		{Source: "statefulset.apps/default/statefulset-foo-1", Target: "pod/default/pod-foo-1", Data: []GraphMLData{{Key: "relation", Value: "ownerReference"}}},
+	}
+
+	if !reflect.DeepEqual(doc.Graph.Edges, expectedEdges) {
+		t.Errorf("Returned edges were incorrect,\ngot:\n%+v\nwant:\n%+v", doc.Graph.Edges, expectedEdges)
+	}
+}
@@ -0,0 +1,246 @@
+package graph
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// HelmManagedByLabel is set to Helm on the objects installed by helm
+	HelmManagedByLabel = "app.kubernetes.io/managed-by"
+	// HelmReleaseNameAnnotation holds the release name of the objects installed by helm
+	HelmReleaseNameAnnotation = "meta.helm.sh/release-name"
+	// HelmReleaseNamespaceAnnotation holds the release namespace of the objects installed by helm
+	HelmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+)
+
+// helmRelease holds the fields used from the release stored by helm
+type helmRelease struct {
+	Name     string `json:"name"`
+	Manifest string `json:"manifest"`
+}
+
+// NewHelmReleaseRoots returns the roots of the objects that belong to the helm release and
+// the namespace of the release. The objects are read from the manifest of the release Secret,
+// or else found by the managed-by label and release-name annotation that helm sets on them.
+// The release is looked for in every namespace, and it must be found in only one of them.
+// The roots are optional, the objects missing from the source are skipped with a warning.
+func NewHelmReleaseRoots(source Source, namespaces []string, release string, chunkSize int64) ([]Root, string, error) {
+	klog.V(1).Infof("get objects of the helm release %s in namespaces %v", release, namespaces)
+
+	objs, namespace, err := getHelmReleaseManifestObjs(source, namespaces, release, chunkSize)
+	if err != nil {
+		return []Root{}, "", err
+	}
+
+	if len(objs) == 0 {
+		klog.V(1).Infof("release secret of %s not found, look for the objects labeled by helm", release)
+
+		objs, namespace, err = getHelmReleaseLabeledObjs(source, namespaces, release, chunkSize)
+		if err != nil {
+			return []Root{}, "", err
+		}
+	}
+
+	roots := []Root{}
+
+	for _, o := range objs {
+		if o.GetNamespace() != namespace {
+			klog.V(2).Infof("object %s not in namespace %s, skip", GetObjID(o), namespace)
+			continue
+		}
+
+		// The objects of the manifest may have been deleted from the cluster
+		roots = append(roots, Root{Kind: getKind(o.GetKind()), Name: o.GetName(), Optional: true})
+	}
+
+	if len(roots) == 0 {
+		return roots, "", fmt.Errorf("helm release '%s' not found in %s", release, getNamespacesDescription(namespaces))
+	}
+
+	return roots, namespace, nil
+}
+
+// getHelmReleaseManifestObjs returns the objects of the manifest of the latest release Secret
+// and the namespace of the Secret. The deployed revision is preferred over the failed or
+// pending ones.
+func getHelmReleaseManifestObjs(source Source, namespaces []string, release string, chunkSize int64) ([]unstructured.Unstructured, string, error) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	secrets := []unstructured.Unstructured{}
+
+	for _, namespace := range namespaces {
+		l, err := listChunks(source, gvr, namespace, metav1.ListOptions{LabelSelector: "owner=helm,name=" + release}, chunkSize, func(unstructured.Unstructured) bool {
+			return true
+		})
+		if err != nil {
+			if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) {
+				klog.V(1).Infof("release secrets of %s could not be listed: %s", release, err)
+				continue
+			}
+
+			return []unstructured.Unstructured{}, "", err
+		}
+
+		secrets = append(secrets, l...)
+	}
+
+	releaseNamespaces := []string{}
+	for _, s := range secrets {
+		if !Contains(s.GetNamespace(), releaseNamespaces) {
+			releaseNamespaces = append(releaseNamespaces, s.GetNamespace())
+		}
+	}
+
+	if len(releaseNamespaces) > 1 {
+		return []unstructured.Unstructured{}, "", fmt.Errorf("helm release '%s' found in several namespaces %v", release, releaseNamespaces)
+	}
+
+	var latest *unstructured.Unstructured
+
+	for i := range secrets {
+		if latest == nil || isLaterHelmRevision(secrets[i], *latest) {
+			latest = &secrets[i]
+		}
+	}
+
+	if latest == nil {
+		return []unstructured.Unstructured{}, "", nil
+	}
+
+	klog.V(2).Infof("read helm release secret %s/%s", latest.GetNamespace(), latest.GetName())
+
+	r, err := decodeHelmRelease(*latest)
+	if err != nil {
+		return []unstructured.Unstructured{}, "", fmt.Errorf("helm release secret '%s' can not be read: %v", latest.GetName(), err)
+	}
+
+	objs, err := loadManifestData([]byte(r.Manifest), latest.GetName(), latest.GetNamespace())
+
+	return objs, latest.GetNamespace(), err
+}
+
+// isLaterHelmRevision returns true if the release secret a should be used instead of b
+func isLaterHelmRevision(a, b unstructured.Unstructured) bool {
+	aDeployed := a.GetLabels()["status"] == "deployed"
+	bDeployed := b.GetLabels()["status"] == "deployed"
+
+	if aDeployed != bDeployed {
+		return aDeployed
+	}
+
+	aVersion, _ := strconv.Atoi(a.GetLabels()["version"])
+	bVersion, _ := strconv.Atoi(b.GetLabels()["version"])
+
+	return aVersion > bVersion
+}
+
+// decodeHelmRelease returns the release stored in the secret. Helm stores the
+// release as gzipped JSON encoded in base64, inside the base64 secret data.
+func decodeHelmRelease(secret unstructured.Unstructured) (helmRelease, error) {
+	r := helmRelease{}
+
+	data, found, err := unstructured.NestedString(secret.Object, "data", "release")
+	if err != nil || !found {
+		return r, fmt.Errorf("release data not found")
+	}
+
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return r, err
+	}
+
+	b, err = base64.StdEncoding.DecodeString(string(b))
+	if err != nil {
+		return r, err
+	}
+
+	// The release is gzipped since helm 3
+	if len(b) > 2 && b[0] == 0x1f && b[1] == 0x8b {
+		gr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return r, err
+		}
+		defer gr.Close()
+
+		b, err = io.ReadAll(gr)
+		if err != nil {
+			return r, err
+		}
+	}
+
+	err = json.Unmarshal(b, &r)
+
+	return r, err
+}
+
+// getHelmReleaseLabeledObjs returns the objects of the supported kinds that helm labeled
+// as managed by it and annotated with the release name, and the namespace of the release.
+// The namespace is read from the release-namespace annotation, or else from the object.
+func getHelmReleaseLabeledObjs(source Source, namespaces []string, release string, chunkSize int64) ([]unstructured.Unstructured, string, error) {
+	objs := []unstructured.Unstructured{}
+	releaseNamespaces := []string{}
+
+	for _, k := range supportedKinds {
+		gvr, err := getGroupVersionResource(k)
+		if err != nil {
+			return objs, "", err
+		}
+
+		for _, namespace := range namespaces {
+			l, err := listChunks(source, gvr, namespace, metav1.ListOptions{LabelSelector: HelmManagedByLabel + "=Helm"}, chunkSize, func(o unstructured.Unstructured) bool {
+				return o.GetAnnotations()[HelmReleaseNameAnnotation] == release
+			})
+			if err != nil {
+				if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) {
+					klog.V(1).Infof("'%s' could not be listed: %s", gvr.GroupResource(), err)
+					continue
+				}
+
+				return objs, "", err
+			}
+
+			for _, o := range l {
+				releaseNamespace := o.GetAnnotations()[HelmReleaseNamespaceAnnotation]
+				if releaseNamespace == "" {
+					releaseNamespace = o.GetNamespace()
+				}
+
+				if !Contains(releaseNamespace, releaseNamespaces) {
+					releaseNamespaces = append(releaseNamespaces, releaseNamespace)
+				}
+			}
+
+			objs = append(objs, l...)
+		}
+	}
+
+	if len(releaseNamespaces) > 1 {
+		return []unstructured.Unstructured{}, "", fmt.Errorf("helm release '%s' found in several namespaces %v", release, releaseNamespaces)
+	}
+
+	if len(releaseNamespaces) == 0 {
+		return objs, "", nil
+	}
+
+	return objs, releaseNamespaces[0], nil
+}
+
+// getNamespacesDescription returns the namespaces as they are described in the errors
+func getNamespacesDescription(namespaces []string) string {
+	if len(namespaces) == 1 && namespaces[0] == metav1.NamespaceAll {
+		return "any namespace"
+	}
+
+	return fmt.Sprintf("namespaces %v", namespaces)
+}
@@ -0,0 +1,223 @@
+package graph
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// newHelmReleaseSecret returns a release secret encoded as helm stores it
+func newHelmReleaseSecret(t *testing.T, release, version, status, manifest string) unstructured.Unstructured {
+	b := &bytes.Buffer{}
+	w := gzip.NewWriter(b)
+
+	_, err := w.Write([]byte(ToJSON(helmRelease{Name: release, Manifest: manifest})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := base64.StdEncoding.EncodeToString([]byte(base64.StdEncoding.EncodeToString(b.Bytes())))
+
+	s := newTestObj("v1", "Secret", "default", "sh.helm.release.v1."+release+".v"+version, map[string]string{
+		"owner":   "helm",
+		"name":    release,
+		"version": version,
+		"status":  status,
+	})
+	_ = unstructured.SetNestedField(s.Object, data, "data", "release")
+
+	return s
+}
+
+func TestNewHelmReleaseRoots(t *testing.T) {
+	labeledObj := newTestObj("apps/v1", "Deployment", "default", "deployment-bar", map[string]string{HelmManagedByLabel: "Helm"})
+	labeledObj.SetAnnotations(map[string]string{HelmReleaseNameAnnotation: "bar"})
+
+	otherObj := newTestObj("v1", "Service", "default", "service-baz", map[string]string{HelmManagedByLabel: "Helm"})
+	otherObj.SetAnnotations(map[string]string{HelmReleaseNameAnnotation: "baz"})
+
+	s := NewObjectSource([]unstructured.Unstructured{
+		newHelmReleaseSecret(t, "foo", "1", "superseded", "apiVersion: v1\nkind: Service\nmetadata:\n  name: service-old\n"),
+		newHelmReleaseSecret(t, "foo", "2", "deployed", manifestFoo),
+		newHelmReleaseSecret(t, "foo", "3", "failed", "apiVersion: v1\nkind: Service\nmetadata:\n  name: service-failed\n"),
+		labeledObj,
+		otherObj,
+	})
+
+	tests := []struct {
+		Release  string
+		Expected []Root
+		Error    bool
+	}{
+		{
+			"foo",
+			[]Root{{Kind: "deployment", Name: "deployment-foo", Optional: true}, {Kind: "service", Name: "service-foo", Optional: true}},
+			false,
+		},
+		{
+			"bar",
+			[]Root{{Kind: "deployment", Name: "deployment-bar", Optional: true}},
+			false,
+		},
+		{
+			"qux",
+			[]Root{},
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		roots, _, err := NewHelmReleaseRoots(s, []string{"default"}, test.Release, 1)
+
+		if (err != nil) != test.Error {
+			t.Errorf("Returned error was incorrect for %s, got: %v", test.Release, err)
+			continue
+		}
+
+		if err == nil && !reflect.DeepEqual(roots, test.Expected) {
+			t.Errorf("Returned result was incorrect, got: %v want: %v", roots, test.Expected)
+		}
+	}
+}
+
+func TestNewHelmReleaseRootsNamespaces(t *testing.T) {
+	secretBar := newHelmReleaseSecret(t, "foo", "1", "deployed", "apiVersion: v1\nkind: Service\nmetadata:\n  name: service-bar\n")
+	secretBar.SetNamespace("bar")
+
+	secretBaz := newHelmReleaseSecret(t, "foo", "1", "deployed", "apiVersion: v1\nkind: Service\nmetadata:\n  name: service-baz\n")
+	secretBaz.SetNamespace("baz")
+
+	// The objects of the release qux are annotated with the namespace of the release
+	labeledObj := newTestObj("v1", "Service", "bar", "service-qux", map[string]string{HelmManagedByLabel: "Helm"})
+	labeledObj.SetAnnotations(map[string]string{HelmReleaseNameAnnotation: "qux", HelmReleaseNamespaceAnnotation: "bar"})
+
+	otherLabeledObj := newTestObj("v1", "Service", "baz", "service-qux", map[string]string{HelmManagedByLabel: "Helm"})
+	otherLabeledObj.SetAnnotations(map[string]string{HelmReleaseNameAnnotation: "qux", HelmReleaseNamespaceAnnotation: "baz"})
+
+	tests := []struct {
+		Objs              []unstructured.Unstructured
+		Namespaces        []string
+		Release           string
+		ExpectedRoots     []Root
+		ExpectedNamespace string
+		Error             bool
+	}{
+		{
+			[]unstructured.Unstructured{secretBar},
+			[]string{""},
+			"foo",
+			[]Root{{Kind: "service", Name: "service-bar", Optional: true}},
+			"bar",
+			false,
+		},
+		{
+			[]unstructured.Unstructured{secretBar, secretBaz},
+			[]string{""},
+			"foo",
+			[]Root{},
+			"",
+			true,
+		},
+		{
+			[]unstructured.Unstructured{secretBar, secretBaz},
+			[]string{"default", "baz"},
+			"foo",
+			[]Root{{Kind: "service", Name: "service-baz", Optional: true}},
+			"baz",
+			false,
+		},
+		{
+			[]unstructured.Unstructured{labeledObj},
+			[]string{""},
+			"qux",
+			[]Root{{Kind: "service", Name: "service-qux", Optional: true}},
+			"bar",
+			false,
+		},
+		{
+			[]unstructured.Unstructured{labeledObj, otherLabeledObj},
+			[]string{""},
+			"qux",
+			[]Root{},
+			"",
+			true,
+		},
+		{
+			[]unstructured.Unstructured{secretBar},
+			[]string{"default"},
+			"foo",
+			[]Root{},
+			"",
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		// The chunk size of 1 makes the source return one object per page
+		roots, namespace, err := NewHelmReleaseRoots(NewObjectSource(test.Objs), test.Namespaces, test.Release, 1)
+
+		if (err != nil) != test.Error {
+			t.Errorf("Returned error was incorrect for %s in %v, got: %v", test.Release, test.Namespaces, err)
+			continue
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if !reflect.DeepEqual(roots, test.ExpectedRoots) {
+			t.Errorf("Returned roots were incorrect, got: %v want: %v", roots, test.ExpectedRoots)
+		}
+
+		if namespace != test.ExpectedNamespace {
+			t.Errorf("Returned namespace was incorrect, got: %v want: %v", namespace, test.ExpectedNamespace)
+		}
+	}
+}
+
+func TestBuildHelmReleaseMissingObject(t *testing.T) {
+	manifest := manifestFoo + "---\napiVersion: v1\nkind: Service\nmetadata:\n  name: service-missing\n"
+
+	objs, err := loadManifestData([]byte(manifestFoo), "foo.yaml", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The service-missing object of the release manifest is not in the source
+	s := NewObjectSource(append(inferObjects(objs), newHelmReleaseSecret(t, "foo", "1", "deployed", manifest)))
+
+	roots, _, err := NewHelmReleaseRoots(s, []string{"default"}, "foo", 0)
+	if err != nil {
+		t.Fatalf("Helm release roots could not be found. Error: %q", err)
+	}
+
+	o := &bytes.Buffer{}
+
+	b := NewBuilder(s, o, OutputTree, []string{"default"}, roots)
+
+	err = b.Build()
+	if err != nil {
+		t.Fatalf("Graph could not be created. Error: %q", err)
+	}
+
+	expected := "\n[Deployment] deployment-foo\n\t└── [ReplicaSet] deployment-foo-49cb98c74f\n\t\t\t┌── [Service] service-foo\n\t\t└── [Pod] deployment-foo-49cb98c74f-5f9c7\n\n"
+
+	if o.String() != expected {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), expected)
+	}
+
+	expectedWarnings := []string{"'service/service-missing' could not be read: not found"}
+
+	if !reflect.DeepEqual(b.Warnings, expectedWarnings) {
+		t.Errorf("Returned warnings were incorrect, got: %v want: %v", b.Warnings, expectedWarnings)
+	}
+}
@@ -0,0 +1,200 @@
+package graph
+
+import (
+	// The page template is embedded so the binary prints it without other files
+	_ "embed"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+//go:embed html.tmpl
+var htmlTemplate string
+
+// HTMLGraph holds the nodes and edges drawn by the script of the HTML page
+type HTMLGraph struct {
+	Nodes []HTMLNode  `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// HTMLNode holds a node, the column where it is drawn and the details shown when
+// it is clicked. The columns follow the kinds from the upper to the lower kinds.
+type HTMLNode struct {
+	GraphNode
+	Label           string      `json:"label"`
+	Column          int         `json:"column"`
+	OwnerReferences []string    `json:"ownerReferences"`
+	Fields          []HTMLField `json:"fields"`
+}
+
+// HTMLField holds a key spec field of an object, e.g. the replicas of a deployment
+type HTMLField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// createHTMLGraph returns a string holding an HTML page that draws the graph of the objects
+// data. The page has no external resources, so it can be opened offline.
+func createHTMLGraph(objsData []ObjData) (string, error) {
+	t, err := template.New("html").Parse(htmlTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	b := &strings.Builder{}
+
+	err = t.Execute(b, NewHTMLGraph(objsData))
+	if err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// NewHTMLGraph returns the nodes and edges of the objects data with their details
+func NewHTMLGraph(objsData []ObjData) *HTMLGraph {
+	g := NewGraph(objsData)
+
+	h := &HTMLGraph{
+		Nodes: []HTMLNode{},
+		Edges: g.Edges,
+	}
+
+	objs := map[string]ObjData{}
+	getObjsDataByID(objsData, objs)
+
+	// The kinds without objects do not get a column
+	ranks := []int{}
+	for _, n := range g.Nodes {
+		if r := getKindRank(n.Kind); !containsInt(r, ranks) {
+			ranks = append(ranks, r)
+		}
+	}
+	sort.Ints(ranks)
+
+	for _, n := range g.Nodes {
+		o := objs[n.ID]
+
+		node := HTMLNode{
+			GraphNode:       n,
+			Label:           getGraphNodeLabel(n),
+			OwnerReferences: []string{},
+			Fields:          []HTMLField{},
+		}
+
+		for i, r := range ranks {
+			if r == getKindRank(n.Kind) {
+				node.Column = i
+			}
+		}
+
+		if o.Unknown == "" {
+			for _, r := range o.Obj.GetOwnerReferences() {
+				node.OwnerReferences = append(node.OwnerReferences, r.Kind+"/"+r.Name)
+			}
+
+			node.Fields = getKeySpecFields(o.Obj)
+		}
+
+		h.Nodes = append(h.Nodes, node)
+	}
+
+	return h
+}
+
+// getObjsDataByID adds the objects data and their related objects data to the map by their ID
+func getObjsDataByID(objsData []ObjData, objs map[string]ObjData) {
+	for _, o := range objsData {
+		objs[getObjDataID(o)] = o
+		getObjsDataByID(o.RelatedObjsData, objs)
+	}
+}
+
+// getKindRank returns the position of the kind in the supported kinds,
+// the kinds that are not supported are placed after them
+func getKindRank(kind string) int {
+	for i, k := range supportedKinds {
+		if k == strings.ToLower(kind) {
+			return i
+		}
+	}
+
+	return len(supportedKinds)
+}
+
+// containsInt returns true if the number is in the list
+func containsInt(i int, list []int) bool {
+	for _, l := range list {
+		if l == i {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getKeySpecFields returns the spec fields of the object shown in its details:
+// the replicas, selector and images of the controllers and pods, the type, cluster
+// IP and ports of the services and the class and hosts of the ingresses
+func getKeySpecFields(obj unstructured.Unstructured) []HTMLField {
+	fields := []HTMLField{}
+
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, HTMLField{Name: name, Value: value})
+		}
+	}
+
+	switch strings.ToLower(obj.GetKind()) {
+	case "pod":
+		nodeName, _, _ := unstructured.NestedString(obj.Object, "spec", "nodeName")
+		add("nodeName", nodeName)
+		add("images", getImages(obj, "spec", "containers"))
+	case "deployment", "replicaset", "statefulset", "daemonset":
+		if replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); found {
+			add("replicas", fmt.Sprint(replicas))
+		}
+
+		selector, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector", "matchLabels")
+		add("selector", labels.Set(selector).String())
+		add("images", getImages(obj, "spec", "template", "spec", "containers"))
+	case "service":
+		serviceType, _, _ := unstructured.NestedString(obj.Object, "spec", "type")
+		add("type", serviceType)
+
+		clusterIP, _, _ := unstructured.NestedString(obj.Object, "spec", "clusterIP")
+		add("clusterIP", clusterIP)
+
+		selector, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector")
+		add("selector", labels.Set(selector).String())
+
+		// The ports and hosts are shown as in the wide output
+		add("ports", getServicePorts(obj))
+	case "ingress":
+		class, _, _ := unstructured.NestedString(obj.Object, "spec", "ingressClassName")
+		add("ingressClassName", class)
+		add("hosts", getIngressHosts(obj))
+	}
+
+	return fields
+}
+
+// getImages returns the images of the containers in the fields of the object
+func getImages(obj unstructured.Unstructured, fields ...string) string {
+	containers, _, _ := unstructured.NestedSlice(obj.Object, fields...)
+	images := []string{}
+
+	for _, c := range containers {
+		if m, ok := c.(map[string]interface{}); ok {
+			if i, ok := m["image"].(string); ok {
+				images = append(images, i)
+			}
+		}
+	}
+
+	return strings.Join(images, ", ")
+}
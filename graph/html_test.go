@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetKeySpecFields(t *testing.T) {
+	tests := []struct {
+		Obj            map[string]interface{}
+		ExpectedFields []HTMLField
+	}{
+		{
+			map[string]interface{}{
+				"kind": "Pod",
+				"spec": map[string]interface{}{
+					"nodeName":   "node-1",
+					"containers": []interface{}{map[string]interface{}{"image": "nginx:1.21"}, map[string]interface{}{"image": "envoy:1.19"}},
+				},
+			},
+			[]HTMLField{{"nodeName", "node-1"}, {"images", "nginx:1.21, envoy:1.19"}},
+		},
+		{
+			map[string]interface{}{
+				"kind": "Deployment",
+				"spec": map[string]interface{}{
+					"replicas": int64(3),
+					"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "foo"}},
+					"template": map[string]interface{}{"spec": map[string]interface{}{"containers": []interface{}{map[string]interface{}{"image": "foo:v1"}}}},
+				},
+			},
+			[]HTMLField{{"replicas", "3"}, {"selector", "app=foo"}, {"images", "foo:v1"}},
+		},
+		{
+			map[string]interface{}{
+				"kind": "Service",
+				"spec": map[string]interface{}{
+					"type":      "ClusterIP",
+					"clusterIP": "10.0.0.10",
+					"ports":     []interface{}{map[string]interface{}{"port": int64(80), "targetPort": int64(8080), "protocol": "TCP"}, map[string]interface{}{"port": int64(443), "nodePort": int64(30443)}},
+				},
+			},
+			[]HTMLField{{"type", "ClusterIP"}, {"clusterIP", "10.0.0.10"}, {"ports", "80/TCP,443:30443/TCP"}},
+		},
+		{
+			map[string]interface{}{
+				"kind": "Ingress",
+				"spec": map[string]interface{}{
+					"ingressClassName": "nginx",
+					"rules":            []interface{}{map[string]interface{}{"host": "foo.example.com"}, map[string]interface{}{}},
+				},
+			},
+			[]HTMLField{{"ingressClassName", "nginx"}, {"hosts", "foo.example.com,*"}},
+		},
+	}
+
+	for _, test := range tests {
+		obj := unstructured.Unstructured{Object: test.Obj}
+		fields := getKeySpecFields(obj)
+
+		if !reflect.DeepEqual(fields, test.ExpectedFields) {
+			t.Errorf("Returned fields of %s were incorrect, got: %v, want: %v", obj.GetKind(), fields, test.ExpectedFields)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package graph
+
+import (
+	"sort"
+	"strings"
+)
+
+// Sizes of the layout in pixels. The text is measured as monospace characters.
+const (
+	layoutCharWidth  = 12
+	layoutLineHeight = 18
+	layoutPadding    = 10
+	layoutColumnGap  = 80
+	layoutRowGap     = 20
+	layoutMargin     = 20
+)
+
+// Layout holds the position of the nodes and edges of a graph drawn as an image
+type Layout struct {
+	Nodes  []LayoutNode
+	Edges  []LayoutEdge
+	Width  int
+	Height int
+}
+
+// LayoutNode holds a node, its lines of text and the box where it is drawn
+type LayoutNode struct {
+	GraphNode
+	Lines  []string
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// LayoutEdge holds an edge and the line from the right side of the source
+// node to the left side of the target node
+type LayoutEdge struct {
+	GraphEdge
+	X1 int
+	Y1 int
+	X2 int
+	Y2 int
+}
+
+// NewLayout returns a layered layout of the graph from left to right. Each node is placed
+// in the column after its farthest source node, and the nodes of a column are sorted by
+// the position of their source and target nodes so fewer edges cross.
+func NewLayout(g *Graph) *Layout {
+	l := &Layout{
+		Nodes: []LayoutNode{},
+		Edges: []LayoutEdge{},
+	}
+
+	index := map[string]int{}
+	for i, n := range g.Nodes {
+		index[n.ID] = i
+	}
+
+	columns := getLayoutColumns(g, index)
+	sortLayoutColumns(g, index, columns)
+
+	widths := make([]int, len(columns))
+	heights := make([]int, len(columns))
+	nodes := make([]LayoutNode, len(g.Nodes))
+
+	for c, column := range columns {
+		for _, i := range column {
+			n := getLayoutNode(g.Nodes[i])
+
+			if n.Width > widths[c] {
+				widths[c] = n.Width
+			}
+			heights[c] = heights[c] + n.Height + layoutRowGap
+
+			nodes[i] = n
+		}
+	}
+
+	for _, h := range heights {
+		if h-layoutRowGap > l.Height {
+			l.Height = h - layoutRowGap
+		}
+	}
+
+	// The columns are centered vertically
+	x := layoutMargin
+
+	for c, column := range columns {
+		y := layoutMargin + (l.Height-heights[c]+layoutRowGap)/2
+
+		for _, i := range column {
+			nodes[i].X = x
+			nodes[i].Y = y
+			y = y + nodes[i].Height + layoutRowGap
+		}
+
+		x = x + widths[c] + layoutColumnGap
+	}
+
+	l.Width = x - layoutColumnGap + layoutMargin
+	l.Height = l.Height + 2*layoutMargin
+
+	if len(columns) == 0 {
+		l.Width = 2 * layoutMargin
+	}
+
+	l.Nodes = nodes
+
+	for _, e := range g.Edges {
+		s, t := nodes[index[e.Source]], nodes[index[e.Target]]
+
+		l.Edges = append(l.Edges, LayoutEdge{
+			GraphEdge: e,
+			X1:        s.X + s.Width,
+			Y1:        s.Y + s.Height/2,
+			X2:        t.X,
+			Y2:        t.Y + t.Height/2,
+		})
+	}
+
+	return l
+}
+
+// getLayoutNode returns the node with its lines of text and size: the kind and name,
+// and the namespace and status
+func getLayoutNode(n GraphNode) LayoutNode {
+	details := []string{}
+	for _, d := range []string{n.Namespace, n.Status} {
+		if d != "" {
+			details = append(details, d)
+		}
+	}
+
+	lines := []string{getGraphNodeLabel(n), strings.Join(details, ", ")}
+
+	chars := 0
+	for _, line := range lines {
+		if len(line) > chars {
+			chars = len(line)
+		}
+	}
+
+	return LayoutNode{
+		GraphNode: n,
+		Lines:     lines,
+		Width:     chars*layoutCharWidth + 2*layoutPadding,
+		Height:    len(lines)*layoutLineHeight + 2*layoutPadding,
+	}
+}
+
+// getLayoutColumns returns the indexes of the nodes in each column. The column of
+// a node is the length of the longest path from a node without source nodes.
+func getLayoutColumns(g *Graph, index map[string]int) [][]int {
+	column := make([]int, len(g.Nodes))
+
+	// A path is never longer than the number of nodes, this also stops on cycles
+	for i := 0; i < len(g.Nodes); i++ {
+		changed := false
+
+		for _, e := range g.Edges {
+			s, t := index[e.Source], index[e.Target]
+
+			if column[t] < column[s]+1 {
+				column[t] = column[s] + 1
+				changed = true
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	// The nodes without source nodes are moved next to their closest target node,
+	// e.g. a statefulset is placed next to its pods instead of next to an ingress
+	for i := range g.Nodes {
+		closest := -1
+
+		for _, e := range g.Edges {
+			if index[e.Target] == i {
+				closest = -1
+				break
+			}
+
+			if index[e.Source] == i && (closest == -1 || column[index[e.Target]] < closest) {
+				closest = column[index[e.Target]]
+			}
+		}
+
+		if closest > 0 {
+			column[i] = closest - 1
+		}
+	}
+
+	columns := [][]int{}
+
+	for i, c := range column {
+		for len(columns) <= c {
+			columns = append(columns, []int{})
+		}
+
+		columns[c] = append(columns[c], i)
+	}
+
+	// The columns without nodes are removed, e.g. after a cycle
+	nonEmpty := [][]int{}
+	for _, c := range columns {
+		if len(c) > 0 {
+			nonEmpty = append(nonEmpty, c)
+		}
+	}
+
+	return nonEmpty
+}
+
+// sortLayoutColumns sorts the nodes of each column by the average position of their
+// source nodes, and then by the average position of their target nodes
+func sortLayoutColumns(g *Graph, index map[string]int, columns [][]int) {
+	position := map[int]int{}
+	for _, column := range columns {
+		for p, i := range column {
+			position[i] = p
+		}
+	}
+
+	sortColumn := func(column []int, neighbors func(i int) []int) {
+		barycenter := map[int]float64{}
+
+		for _, i := range column {
+			barycenter[i] = float64(position[i])
+
+			if n := neighbors(i); len(n) > 0 {
+				sum := 0
+				for _, j := range n {
+					sum = sum + position[j]
+				}
+
+				barycenter[i] = float64(sum) / float64(len(n))
+			}
+		}
+
+		sort.SliceStable(column, func(a, b int) bool {
+			return barycenter[column[a]] < barycenter[column[b]]
+		})
+
+		for p, i := range column {
+			position[i] = p
+		}
+	}
+
+	sources := func(i int) []int {
+		n := []int{}
+		for _, e := range g.Edges {
+			if index[e.Target] == i {
+				n = append(n, index[e.Source])
+			}
+		}
+		return n
+	}
+
+	targets := func(i int) []int {
+		n := []int{}
+		for _, e := range g.Edges {
+			if index[e.Source] == i {
+				n = append(n, index[e.Target])
+			}
+		}
+		return n
+	}
+
+	for c := 1; c < len(columns); c++ {
+		sortColumn(columns[c], sources)
+	}
+
+	for c := len(columns) - 2; c >= 0; c-- {
+		sortColumn(columns[c], targets)
+	}
+}
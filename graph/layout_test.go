@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"testing"
+)
+
+func TestNewLayout(t *testing.T) {
+	g := &Graph{
+		Nodes: []GraphNode{
+			{ID: "ingress", Kind: "Ingress", Name: "foo"},
+			{ID: "service", Kind: "Service", Name: "foo"},
+			{ID: "deployment", Kind: "Deployment", Name: "foo"},
+			{ID: "replicaset", Kind: "ReplicaSet", Name: "foo-1"},
+			{ID: "pod-1", Kind: "Pod", Name: "foo-1-a"},
+			{ID: "pod-2", Kind: "Pod", Name: "foo-1-b"},
+		},
+		Edges: []GraphEdge{
+			{Source: "ingress", Target: "service"},
+			{Source: "service", Target: "pod-1"},
+			{Source: "service", Target: "pod-2"},
+			{Source: "deployment", Target: "replicaset"},
+			{Source: "replicaset", Target: "pod-1"},
+			{Source: "replicaset", Target: "pod-2"},
+		},
+	}
+
+	l := NewLayout(g)
+
+	columns := map[string]int{}
+	for _, n := range l.Nodes {
+		columns[n.ID] = n.X
+	}
+
+	// The service is a source of the pods like the replicaset, so it is placed in the same column
+	if columns["service"] != columns["replicaset"] {
+		t.Errorf("Service and replicaset were not placed in the same column, got: %d and %d", columns["service"], columns["replicaset"])
+	}
+
+	for _, e := range l.Edges {
+		if e.X1 >= e.X2 {
+			t.Errorf("Edge from %s to %s does not go from left to right, got: %d to %d", e.Source, e.Target, e.X1, e.X2)
+		}
+	}
+
+	for i, a := range l.Nodes {
+		if a.X+a.Width > l.Width || a.Y+a.Height > l.Height {
+			t.Errorf("Node %s is outside of the layout", a.ID)
+		}
+
+		for _, b := range l.Nodes[i+1:] {
+			if a.X < b.X+b.Width && b.X < a.X+a.Width && a.Y < b.Y+b.Height && b.Y < a.Y+a.Height {
+				t.Errorf("Nodes %s and %s overlap", a.ID, b.ID)
+			}
+		}
+	}
+}
+
+func TestNewLayoutEmpty(t *testing.T) {
+	l := NewLayout(&Graph{})
+
+	if l.Width != 2*layoutMargin || l.Height != 2*layoutMargin {
+		t.Errorf("Returned size of the empty layout was incorrect, got: %dx%d", l.Width, l.Height)
+	}
+}
@@ -0,0 +1,240 @@
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"os/exec"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/klog/v2"
+)
+
+// InferredAnnotation is set on the objects inferred from the templates of the manifests
+const InferredAnnotation = "kubegraph.io/inferred"
+
+// helmCommand is the helm binary used to render the charts
+var helmCommand = "helm"
+
+// NewManifestSource returns a source that holds the objects of the YAML or JSON manifests
+// found in the filenames, a filename can be a file, a directory or - to read from stdin.
+// The objects without a namespace are added to the namespace. The replicasets and pods
+// the controllers would create from their templates are inferred, so the ownerReferences
+// and label selector relations work as they do in a cluster.
+func NewManifestSource(filenames []string, recursive bool, namespace string) (*ObjectSource, error) {
+	objs, err := LoadManifests(filenames, recursive, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewObjectSource(inferObjects(objs)), nil
+}
+
+// NewKustomizeSource returns a source that holds the objects built from the kustomization
+// directory, like kubectl apply -k does. The objects are completed as in NewManifestSource.
+func NewKustomizeSource(dir string, namespace string) (*ObjectSource, error) {
+	objs, err := LoadKustomization(dir, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewObjectSource(inferObjects(objs)), nil
+}
+
+// NewHelmChartSource returns a source that holds the objects of the chart rendered locally
+// with helm template and the values files. The objects are completed as in NewManifestSource.
+func NewHelmChartSource(chart string, valuesFiles []string, namespace string) (*ObjectSource, error) {
+	objs, err := LoadHelmChart(chart, valuesFiles, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewObjectSource(inferObjects(objs)), nil
+}
+
+// LoadManifests returns the objects of the supported kinds found in the manifests
+func LoadManifests(filenames []string, recursive bool, namespace string) ([]unstructured.Unstructured, error) {
+	klog.V(1).Infof("load manifests %v", filenames)
+
+	r := resource.NewLocalBuilder().
+		Unstructured().
+		FilenameParam(false, &resource.FilenameOptions{Filenames: filenames, Recursive: recursive}).
+		Flatten().
+		Do()
+
+	return loadObjects(r, namespace)
+}
+
+// LoadKustomization returns the objects of the supported kinds built from the kustomization directory
+func LoadKustomization(dir string, namespace string) ([]unstructured.Unstructured, error) {
+	klog.V(1).Infof("load kustomization %s", dir)
+
+	r := resource.NewLocalBuilder().
+		Unstructured().
+		FilenameParam(false, &resource.FilenameOptions{Kustomize: dir}).
+		Flatten().
+		Do()
+
+	return loadObjects(r, namespace)
+}
+
+// LoadHelmChart returns the objects of the supported kinds rendered from the chart.
+// The chart can be a directory, a packaged chart or a repo/chart reference, as
+// accepted by helm template.
+func LoadHelmChart(chart string, valuesFiles []string, namespace string) ([]unstructured.Unstructured, error) {
+	klog.V(1).Infof("load helm chart %s with values %v", chart, valuesFiles)
+
+	args := []string{"template", chart}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	for _, v := range valuesFiles {
+		args = append(args, "--values", v)
+	}
+
+	klog.V(2).Infof("run %s %s", helmCommand, strings.Join(args, " "))
+
+	stderr := &bytes.Buffer{}
+	cmd := exec.Command(helmCommand, args...)
+	cmd.Stderr = stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return []unstructured.Unstructured{}, fmt.Errorf("chart '%s' could not be rendered: %v %s", chart, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return loadManifestData(out, chart, namespace)
+}
+
+// loadManifestData returns the objects of the supported kinds found in the manifest data
+func loadManifestData(data []byte, source string, namespace string) ([]unstructured.Unstructured, error) {
+	r := resource.NewLocalBuilder().
+		Unstructured().
+		Stream(bytes.NewReader(data), source).
+		Flatten().
+		Do()
+
+	return loadObjects(r, namespace)
+}
+
+// loadObjects returns the objects of the supported kinds of the result. The
+// objects without a namespace are added to the namespace.
+func loadObjects(r *resource.Result, namespace string) ([]unstructured.Unstructured, error) {
+	objs := []unstructured.Unstructured{}
+
+	infos, err := r.Infos()
+	if err != nil {
+		return objs, err
+	}
+
+	for _, i := range infos {
+		u, ok := i.Object.(*unstructured.Unstructured)
+		if !ok {
+			return objs, fmt.Errorf("object '%s' of '%s' can not be read", i.Name, i.Source)
+		}
+
+		if !Contains(getKind(u.GetKind()), supportedKinds) {
+			klog.V(2).Infof("kind '%s' of '%s' not supported, skip", u.GetKind(), i.Source)
+			continue
+		}
+
+		// A supported kind of another group, e.g. a custom resource, would never be listed
+		gvr, _ := getGroupVersionResource(getKind(u.GetKind()))
+		if !isResource(*u, gvr) {
+			klog.Warningf("'%s' of '%s' is not a %s, skip", u.GetAPIVersion()+"/"+u.GetKind(), i.Source, gvr.GroupResource())
+			continue
+		}
+
+		if u.GetNamespace() == "" {
+			u.SetNamespace(namespace)
+		}
+
+		objs = append(objs, *u)
+	}
+
+	return objs, nil
+}
+
+// inferObjects returns the objects with a UID, plus the replicasets of the deployments
+// and the pods of the replicasets, statefulsets and daemonsets built from their templates
+func inferObjects(objs []unstructured.Unstructured) []unstructured.Unstructured {
+	inferredObjs := []unstructured.Unstructured{}
+
+	for len(objs) > 0 {
+		o := objs[0]
+		objs = objs[1:]
+
+		if o.GetUID() == "" {
+			o.SetUID(types.UID(GetObjID(o)))
+		}
+		inferredObjs = append(inferredObjs, o)
+
+		switch getKind(o.GetKind()) {
+		case "deployment":
+			objs = append(objs, newTemplateObj(o, "apps/v1", "ReplicaSet", o.GetName()+"-"+getTemplateHash(o), "replicas", "selector", "template"))
+		case "replicaset", "daemonset":
+			if replicas, found, _ := unstructured.NestedInt64(o.Object, "spec", "replicas"); found && replicas == 0 {
+				continue
+			}
+			objs = append(objs, newTemplateObj(o, "v1", "Pod", o.GetName()+"-"+getTemplateHash(o)[:5]))
+		case "statefulset":
+			objs = append(objs, newTemplateObj(o, "v1", "Pod", o.GetName()+"-0"))
+		}
+	}
+
+	return inferredObjs
+}
+
+// newTemplateObj returns the object that a controller creates from its template. The object
+// gets the labels of the template, and the spec fields of the owner when they are given,
+// e.g. the selector and template of a deployment, or else the spec of the template.
+func newTemplateObj(owner unstructured.Unstructured, apiVersion, kind, name string, specFields ...string) unstructured.Unstructured {
+	o := unstructured.Unstructured{Object: map[string]interface{}{}}
+	o.SetAPIVersion(apiVersion)
+	o.SetKind(kind)
+	o.SetName(name)
+	o.SetNamespace(owner.GetNamespace())
+	o.SetAnnotations(map[string]string{InferredAnnotation: "true"})
+
+	isController := true
+	o.SetOwnerReferences([]metav1.OwnerReference{
+		{
+			APIVersion: owner.GetAPIVersion(),
+			Kind:       owner.GetKind(),
+			Name:       owner.GetName(),
+			UID:        owner.GetUID(),
+			Controller: &isController,
+		},
+	})
+
+	templateLabels, _, _ := unstructured.NestedStringMap(owner.Object, "spec", "template", "metadata", "labels")
+	o.SetLabels(templateLabels)
+
+	if len(specFields) == 0 {
+		if spec, found, _ := unstructured.NestedMap(owner.Object, "spec", "template", "spec"); found {
+			_ = unstructured.SetNestedMap(o.Object, spec, "spec")
+		}
+	}
+
+	for _, f := range specFields {
+		if v, found, _ := unstructured.NestedFieldCopy(owner.Object, "spec", f); found {
+			_ = unstructured.SetNestedField(o.Object, v, "spec", f)
+		}
+	}
+
+	return o
+}
+
+// getTemplateHash returns a hash of the object template used to name the inferred objects
+func getTemplateHash(o unstructured.Unstructured) string {
+	h := fnv.New32a()
+	template, _, _ := unstructured.NestedMap(o.Object, "spec", "template")
+	_, _ = h.Write([]byte(o.GetName() + ToJSON(template)))
+
+	return rand.SafeEncodeString(fmt.Sprintf("%010d", h.Sum32()))
+}
@@ -0,0 +1,363 @@
+package graph
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const manifestFoo = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-foo
+spec:
+  selector:
+    matchLabels:
+      app: foo
+  template:
+    metadata:
+      labels:
+        app: foo
+    spec:
+      containers:
+      - name: foo
+        image: foo
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: service-foo
+spec:
+  selector:
+    app: foo
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: configmap-foo
+`
+
+const manifestBar = `{
+  "apiVersion": "networking.k8s.io/v1",
+  "kind": "Ingress",
+  "metadata": {
+    "name": "ingress-foo"
+  },
+  "spec": {
+    "rules": [
+      {
+        "http": {
+          "paths": [
+            {
+              "path": "/",
+              "pathType": "Prefix",
+              "backend": {
+                "service": {
+                  "name": "service-foo",
+                  "port": {
+                    "number": 80
+                  }
+                }
+              }
+            }
+          ]
+        }
+      }
+    ]
+  }
+}
+`
+
+func TestLoadManifests(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.MkdirAll(filepath.Join(dir, "bar"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "foo.yaml"), []byte(manifestFoo), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "bar", "bar.json"), []byte(manifestBar), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Recursive bool
+		Expected  []string
+	}{
+		{
+			false,
+			[]string{"deployment.apps/default/deployment-foo", "service/default/service-foo"},
+		},
+		{
+			true,
+			[]string{"ingress.networking.k8s.io/default/ingress-foo", "deployment.apps/default/deployment-foo", "service/default/service-foo"},
+		},
+	}
+
+	for _, test := range tests {
+		objs, err := LoadManifests([]string{dir}, test.Recursive, "default")
+		if err != nil {
+			t.Errorf("Manifests could not be loaded. Error: %q", err)
+		}
+
+		ids := []string{}
+		for _, o := range objs {
+			ids = append(ids, GetObjID(o))
+		}
+
+		if len(ids) != len(test.Expected) {
+			t.Errorf("Returned result was incorrect, got: %v want: %v", ids, test.Expected)
+			continue
+		}
+
+		for _, id := range test.Expected {
+			if !Contains(id, ids) {
+				t.Errorf("Returned result was incorrect, got: %v want: %v", ids, test.Expected)
+			}
+		}
+	}
+}
+
+func TestLoadManifestsGroups(t *testing.T) {
+	data := "apiVersion: extensions/v1beta1\nkind: Ingress\nmetadata:\n  name: ingress-foo\n" +
+		"---\napiVersion: example.com/v1\nkind: Service\nmetadata:\n  name: service-foo\n"
+
+	objs, err := loadManifestData([]byte(data), "stdin", "default")
+	if err != nil {
+		t.Fatalf("Manifests could not be loaded. Error: %q", err)
+	}
+
+	ids := []string{}
+	for _, o := range objs {
+		ids = append(ids, GetObjID(o))
+	}
+
+	// The legacy ingress is kept and the service of another group is skipped
+	expected := []string{"ingress.extensions/default/ingress-foo"}
+
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Returned result was incorrect, got: %v want: %v", ids, expected)
+	}
+}
+
+func TestBuildManifests(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "foo.yaml")
+
+	err := os.WriteFile(filename, []byte(manifestFoo+"---\n"+manifestBar), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewManifestSource([]string{filename}, false, "default")
+	if err != nil {
+		t.Fatalf("Manifest source could not be created. Error: %q", err)
+	}
+
+	o := &bytes.Buffer{}
+
+	b := NewBuilder(s, o, OutputTree, []string{"default"}, []Root{{Kind: "ingress", Name: "ingress-foo"}})
+
+	err = b.Build()
+	if err != nil {
+		t.Errorf("Graph could not be created. Error: %q", err)
+	}
+
+	expected := "\n[Ingress] ingress-foo\n\t└── [Service] service-foo\n\t\t\t\t┌── [Deployment] deployment-foo\n\t\t\t┌── [ReplicaSet] deployment-foo-49cb98c74f\n\t\t└── [Pod] deployment-foo-49cb98c74f-5f9c7\n\n"
+
+	if o.String() != expected {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), expected)
+	}
+}
+
+func TestBuildManifestsWithoutSpec(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "foo.yaml")
+
+	err := os.WriteFile(filename, []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: service-foo\n---\n"+manifestBar), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewManifestSource([]string{filename}, false, "default")
+	if err != nil {
+		t.Fatalf("Manifest source could not be created. Error: %q", err)
+	}
+
+	o := &bytes.Buffer{}
+
+	// The service without spec selects no pod
+	b := NewBuilder(s, o, OutputTree, []string{"default"}, []Root{{}})
+
+	err = b.Build()
+	if err != nil {
+		t.Errorf("Graph could not be created. Error: %q", err)
+	}
+
+	expected := "\n[Ingress] ingress-foo\n\t└── [Service] service-foo\n\n"
+
+	if o.String() != expected {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), expected)
+	}
+}
+
+func TestBuildNamespacedManifests(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "foo.yaml")
+
+	manifest := strings.ReplaceAll(manifestFoo, "metadata:\n  name:", "metadata:\n  namespace: shop\n  name:")
+
+	err := os.WriteFile(filename, []byte(manifest), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewManifestSource([]string{filename}, false, "default")
+	if err != nil {
+		t.Fatalf("Manifest source could not be created. Error: %q", err)
+	}
+
+	namespaces := s.GetNamespaces()
+
+	if !reflect.DeepEqual(namespaces, []string{"shop"}) {
+		t.Errorf("Returned namespaces were incorrect, got: %v want: %v", namespaces, []string{"shop"})
+	}
+
+	o := &bytes.Buffer{}
+
+	b := NewBuilder(s, o, OutputTree, namespaces, []Root{{Kind: "service", Name: "service-foo"}})
+
+	err = b.Build()
+	if err != nil {
+		t.Errorf("Graph could not be created. Error: %q", err)
+	}
+
+	expected := "\n[Service] service-foo\n\t\t\t┌── [Deployment] deployment-foo\n\t\t┌── [ReplicaSet] deployment-foo-49cb98c74f\n\t└── [Pod] deployment-foo-49cb98c74f-5f9c7\n\n"
+
+	if o.String() != expected {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), expected)
+	}
+}
+
+func TestLoadKustomization(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "foo.yaml"), []byte(manifestFoo), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("namePrefix: prod-\nnamespace: shop\nresources:\n- foo.yaml\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objs, err := LoadKustomization(dir, "default")
+	if err != nil {
+		t.Fatalf("Kustomization could not be loaded. Error: %q", err)
+	}
+
+	ids := []string{}
+	for _, o := range objs {
+		ids = append(ids, GetObjID(o))
+	}
+	sort.Strings(ids)
+
+	expected := []string{"deployment.apps/shop/prod-deployment-foo", "service/shop/prod-service-foo"}
+
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Returned result was incorrect, got: %v want: %v", ids, expected)
+	}
+}
+
+func TestBuildNamespacedKustomization(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "foo.yaml"), []byte(manifestFoo), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("namespace: shop\nresources:\n- foo.yaml\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewKustomizeSource(dir, "default")
+	if err != nil {
+		t.Fatalf("Kustomize source could not be created. Error: %q", err)
+	}
+
+	namespaces := s.GetNamespaces()
+
+	if !reflect.DeepEqual(namespaces, []string{"shop"}) {
+		t.Errorf("Returned namespaces were incorrect, got: %v want: %v", namespaces, []string{"shop"})
+	}
+
+	o := &bytes.Buffer{}
+
+	b := NewBuilder(s, o, OutputTree, namespaces, []Root{{Kind: "deployment"}})
+
+	err = b.Build()
+	if err != nil {
+		t.Errorf("Graph could not be created. Error: %q", err)
+	}
+
+	expected := "\n[Deployment] deployment-foo\n\t└── [ReplicaSet] deployment-foo-49cb98c74f\n\t\t\t┌── [Service] service-foo\n\t\t└── [Pod] deployment-foo-49cb98c74f-5f9c7\n\n"
+
+	if o.String() != expected {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), expected)
+	}
+}
+
+func TestLoadHelmChart(t *testing.T) {
+	dir := t.TempDir()
+
+	// The fake helm prints its arguments to a file and the manifest to stdout
+	helm := filepath.Join(dir, "helm")
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\ncat <<'EOF'\n" + manifestFoo + "EOF\n"
+
+	err := os.WriteFile(helm, []byte(script), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(c string) { helmCommand = c }(helmCommand)
+	helmCommand = helm
+
+	objs, err := LoadHelmChart("./chart", []string{"values.yaml", "prod.yaml"}, "shop")
+	if err != nil {
+		t.Fatalf("Helm chart could not be loaded. Error: %q", err)
+	}
+
+	ids := []string{}
+	for _, o := range objs {
+		ids = append(ids, GetObjID(o))
+	}
+
+	expected := []string{"deployment.apps/shop/deployment-foo", "service/shop/service-foo"}
+
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Returned result was incorrect, got: %v want: %v", ids, expected)
+	}
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedArgs := "template ./chart --namespace shop --values values.yaml --values prod.yaml\n"
+
+	if string(args) != expectedArgs {
+		t.Errorf("Returned helm arguments were incorrect, got: %q want: %q", args, expectedArgs)
+	}
+}
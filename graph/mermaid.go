@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// createMermaidGraph returns a string holding the mermaid flowchart of the objects data.
+// The nodes are added to the subgraph of their namespace and the edges are labeled
+// with their relation. The unknown objects are drawn with a dashed border.
+func createMermaidGraph(objsData []ObjData) string {
+	g := NewGraph(objsData)
+
+	// The node IDs of mermaid can not hold slashes or dots
+	ids := map[string]string{}
+	unknown := []string{}
+
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+
+		if n.Unknown != "" {
+			unknown = append(unknown, ids[n.ID])
+		}
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "flowchart LR")
+
+	for _, ns := range getGraphNamespaces(g) {
+		fmt.Fprintf(b, "    subgraph %s[\"%s\"]\n", getMermaidSubgraphID(ns), escapeMermaidLabel(ns))
+
+		for _, n := range g.Nodes {
+			if n.Namespace == ns {
+				fmt.Fprintf(b, "        %s\n", getMermaidNode(ids[n.ID], n))
+			}
+		}
+
+		fmt.Fprintln(b, "    end")
+	}
+
+	for _, n := range g.Nodes {
+		if n.Namespace == "" {
+			fmt.Fprintf(b, "    %s\n", getMermaidNode(ids[n.ID], n))
+		}
+	}
+
+	for _, e := range g.Edges {
+		if e.Relation == "" {
+			fmt.Fprintf(b, "    %s --> %s\n", ids[e.Source], ids[e.Target])
+		} else {
+			fmt.Fprintf(b, "    %s -->|%s| %s\n", ids[e.Source], e.Relation, ids[e.Target])
+		}
+	}
+
+	if len(unknown) > 0 {
+		fmt.Fprintln(b, "    classDef unknown stroke-dasharray: 5 5")
+		fmt.Fprintf(b, "    class %s unknown\n", strings.Join(unknown, ","))
+	}
+
+	return b.String()
+}
+
+// getMermaidNode returns the mermaid node of the graph node, its shape depends on the kind
+func getMermaidNode(id string, n GraphNode) string {
+	label := "\"" + escapeMermaidLabel(getGraphNodeLabel(n)) + "\""
+
+	switch strings.ToLower(n.Kind) {
+	case "ingress":
+		return id + "([" + label + "])"
+	case "service":
+		return id + "{{" + label + "}}"
+	case "deployment", "statefulset", "daemonset":
+		return id + "[[" + label + "]]"
+	case "replicaset":
+		return id + "[/" + label + "/]"
+	case "pod":
+		return id + "(" + label + ")"
+	}
+
+	return id + "[" + label + "]"
+}
+
+// getMermaidSubgraphID returns the ID of the mermaid subgraph of a namespace
+func getMermaidSubgraphID(namespace string) string {
+	return "ns_" + strings.NewReplacer("-", "_", ".", "_").Replace(namespace)
+}
+
+// escapeMermaidLabel returns the label with the characters that end a quoted mermaid label,
+// or that mermaid renders as HTML, escaped as entity codes
+func escapeMermaidLabel(label string) string {
+	return strings.NewReplacer("\"", "#quot;", "<", "#lt;", ">", "#gt;").Replace(label)
+}
@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Graph holds the nodes and edges of the graph printed as JSON or YAML
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode holds an object of the graph. Unknown holds the reason why
+// the objects of the kind could not be listed, the node has no name then.
+type GraphNode struct {
+	ID         string            `json:"id"`
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Namespace  string            `json:"namespace"`
+	Name       string            `json:"name"`
+	UID        string            `json:"uid"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Status     string            `json:"status"`
+	Unknown    string            `json:"unknown,omitempty"`
+}
+
+// GraphEdge holds a relation from the upper source object to the lower
+// target object, and its reason, e.g. ownerReference
+type GraphEdge struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Relation string `json:"relation"`
+}
+
+// NewGraph returns the nodes and edges of the objects data, without duplicates
+func NewGraph(objsData []ObjData) *Graph {
+	g := &Graph{
+		Nodes: []GraphNode{},
+		Edges: []GraphEdge{},
+	}
+
+	ids := map[string]bool{}
+	edges := map[GraphEdge]bool{}
+
+	for _, o := range objsData {
+		g.addObjData(o, ids, edges)
+	}
+
+	return g
+}
+
+// addObjData adds the node of the object and the nodes and edges of its related objects.
+// The added node IDs and edges are tracked in the ids and edges maps.
+func (g *Graph) addObjData(o ObjData, ids map[string]bool, edges map[GraphEdge]bool) {
+	id := getObjDataID(o)
+
+	if !ids[id] {
+		ids[id] = true
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID:         id,
+			APIVersion: o.Obj.GetAPIVersion(),
+			Kind:       o.Obj.GetKind(),
+			Namespace:  o.Obj.GetNamespace(),
+			Name:       o.Obj.GetName(),
+			UID:        string(o.Obj.GetUID()),
+			Labels:     o.Obj.GetLabels(),
+			Status:     getObjStatus(o),
+			Unknown:    o.Unknown,
+		})
+	}
+
+	f := NewFilter()
+
+	for _, r := range o.RelatedObjsData {
+		e := GraphEdge{
+			Source:   id,
+			Target:   getObjDataID(r),
+			Relation: f.GetRelation(o.Obj.GetKind(), r.Obj.GetKind()),
+		}
+
+		if r.Hierarchy == "upper" {
+			e.Source, e.Target = e.Target, e.Source
+		}
+
+		// The relation is given by the kinds, so an edge is keyed by its source, target and relation
+		if !edges[e] {
+			edges[e] = true
+			g.Edges = append(g.Edges, e)
+		}
+
+		g.addObjData(r, ids, edges)
+	}
+}
+
+// getGraphNodeLabel returns the kind and name of the node, or the reason why it is unknown
+func getGraphNodeLabel(n GraphNode) string {
+	if n.Unknown != "" {
+		return fmt.Sprintf("%s: unknown (%s)", n.Kind, n.Unknown)
+	}
+
+	return n.Kind + ": " + n.Name
+}
+
+// getGraphNamespaces returns the sorted namespaces of the nodes
+func getGraphNamespaces(g *Graph) []string {
+	namespaces := []string{}
+
+	for _, n := range g.Nodes {
+		if n.Namespace != "" && !Contains(n.Namespace, namespaces) {
+			namespaces = append(namespaces, n.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+
+	return namespaces
+}
@@ -0,0 +1,81 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestNewGraph(t *testing.T) {
+	s := NewFakeSource("default")
+	s.Forbidden = []string{"ingresses"}
+
+	b := NewBuilder(s, &bytes.Buffer{}, OutputTree, []string{"default"}, []Root{{Kind: "service", Name: "service-foo"}})
+
+	err := b.Collect()
+	if err != nil {
+		t.Fatalf("Graph could not be created. Error: %q", err)
+	}
+
+	expected := &Graph{
+		Nodes: []GraphNode{
+			{ID: "service/default/service-foo", APIVersion: "v1", Kind: "Service", Namespace: "default", Name: "service-foo"},
+			{ID: "ingress/default/(forbidden)", Kind: "Ingress", Namespace: "default", Unknown: "forbidden"},
+			{ID: "pod/default/pod-foo-1", APIVersion: "v1", Kind: "Pod", Namespace: "default", Name: "pod-foo-1", Labels: map[string]string{"app": "foo", "version": "v1"}},
+			{ID: "statefulset.apps/default/statefulset-foo-1", APIVersion: "apps/v1", Kind: "Statefulset", Namespace: "default", Name: "statefulset-foo-1", UID: "1d1fcfc1-6f23-4578-9b70-8361a733ab26"},
+		},
+		Edges: []GraphEdge{
+			{Source: "ingress/default/(forbidden)", Target: "service/default/service-foo", Relation: "ingressBackend"},
+			{Source: "service/default/service-foo", Target: "pod/default/pod-foo-1", Relation: "labelSelector"},
+			{Source: "statefulset.apps/default/statefulset-foo-1", Target: "pod/default/pod-foo-1", Relation: "ownerReference"},
+		},
+	}
+
+	g := NewGraph(b.ObjsData)
+
+	if !reflect.DeepEqual(g, expected) {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%+v\nwant:\n%+v", g, expected)
+	}
+}
+
+func TestPrintJSONAndYAML(t *testing.T) {
+	b := NewBuilder(NewFakeSource("default"), &bytes.Buffer{}, OutputTree, []string{"default"}, []Root{{Kind: "service", Name: "service-foo"}})
+
+	err := b.Collect()
+	if err != nil {
+		t.Fatalf("Graph could not be created. Error: %q", err)
+	}
+
+	expected := NewGraph(b.ObjsData)
+
+	tests := []struct {
+		Output    string
+		Unmarshal func([]byte, interface{}) error
+	}{
+		{OutputJSON, json.Unmarshal},
+		{OutputYAML, func(b []byte, v interface{}) error { return yaml.Unmarshal(b, v) }},
+	}
+
+	for _, test := range tests {
+		o := &bytes.Buffer{}
+
+		err := NewPrinter(b.ObjsData, test.Output, o).Print()
+		if err != nil {
+			t.Fatalf("Graph could not be printed as %s. Error: %q", test.Output, err)
+		}
+
+		g := &Graph{}
+
+		err = test.Unmarshal(o.Bytes(), g)
+		if err != nil {
+			t.Fatalf("Graph printed as %s could not be read. Error: %q", test.Output, err)
+		}
+
+		if !reflect.DeepEqual(g, expected) {
+			t.Errorf("Returned %s graph was incorrect,\ngot:\n%+v\nwant:\n%+v", test.Output, g, expected)
+		}
+	}
+}
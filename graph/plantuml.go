@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// createPlantUMLGraph returns a string holding the PlantUML diagram of the objects data.
+// The nodes have the kind as stereotype and are added to the frame of their namespace,
+// the edges are labeled with their relation and the unknown objects have a dashed border.
+func createPlantUMLGraph(objsData []ObjData) string {
+	g := NewGraph(objsData)
+
+	ids := map[string]string{}
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "@startuml")
+	fmt.Fprintln(b, "left to right direction")
+
+	for i, ns := range getGraphNamespaces(g) {
+		fmt.Fprintf(b, "frame \"%s\" as ns%d {\n", escapePlantUMLLabel(ns), i)
+
+		for _, n := range g.Nodes {
+			if n.Namespace == ns {
+				fmt.Fprintf(b, "  %s\n", getPlantUMLNode(ids[n.ID], n))
+			}
+		}
+
+		fmt.Fprintln(b, "}")
+	}
+
+	for _, n := range g.Nodes {
+		if n.Namespace == "" {
+			fmt.Fprintln(b, getPlantUMLNode(ids[n.ID], n))
+		}
+	}
+
+	for _, e := range g.Edges {
+		if e.Relation == "" {
+			fmt.Fprintf(b, "%s --> %s\n", ids[e.Source], ids[e.Target])
+		} else {
+			fmt.Fprintf(b, "%s --> %s : %s\n", ids[e.Source], ids[e.Target], e.Relation)
+		}
+	}
+
+	fmt.Fprintln(b, "@enduml")
+
+	return b.String()
+}
+
+// getPlantUMLNode returns the PlantUML element of the graph node, its element type depends on the kind
+func getPlantUMLNode(id string, n GraphNode) string {
+	element := "rectangle"
+
+	switch strings.ToLower(n.Kind) {
+	case "ingress":
+		element = "boundary"
+	case "service":
+		element = "interface"
+	case "deployment", "statefulset", "daemonset", "replicaset":
+		element = "collections"
+	case "pod":
+		element = "node"
+	}
+
+	node := fmt.Sprintf("%s \"%s\" <<%s>> as %s", element, escapePlantUMLLabel(getGraphNodeLabel(n)), n.Kind, id)
+
+	if n.Unknown != "" {
+		node = node + " #line.dashed"
+	}
+
+	return node
+}
+
+// escapePlantUMLLabel returns the label with the characters that end a quoted PlantUML label,
+// or that PlantUML reads as escape sequences or creole markup, escaped as character entities
+func escapePlantUMLLabel(label string) string {
+	return strings.NewReplacer("\"", "&#34;", "\\", "&#92;", "<", "&#60;", ">", "&#62;").Replace(label)
+}
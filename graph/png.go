@@ -0,0 +1,184 @@
+package graph
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+)
+
+// pngFontScale is the number of pixels of the image per pixel of a glyph
+const pngFontScale = 2
+
+// createPNGGraph returns a string holding the PNG image of the objects data,
+// drawn with the layout used for the SVG image and the built-in bitmap font
+func createPNGGraph(objsData []ObjData) (string, error) {
+	l := NewLayout(NewGraph(objsData))
+
+	img := image.NewRGBA(image.Rect(0, 0, l.Width, l.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	edgeColor := getColor(imageEdgeStroke)
+
+	for _, e := range l.Edges {
+		drawLine(img, e.X1, e.Y1, e.X2, e.Y2, edgeColor)
+		drawArrowHead(img, e.X1, e.Y1, e.X2, e.Y2, edgeColor)
+	}
+
+	for _, n := range l.Nodes {
+		fill, stroke := getColor(imageNodeFill), getColor(imageNodeStroke)
+		if n.Unknown != "" {
+			fill, stroke = getColor(imageUnknownFill), getColor(imageUnknownStroke)
+		}
+
+		draw.Draw(img, image.Rect(n.X, n.Y, n.X+n.Width, n.Y+n.Height), image.NewUniform(fill), image.Point{}, draw.Src)
+		drawBorder(img, n.X, n.Y, n.Width, n.Height, stroke, n.Unknown != "")
+
+		for i, line := range n.Lines {
+			c := getColor(imageTextFill)
+			if i > 0 {
+				c = getColor(imageDetailsFill)
+			}
+
+			drawText(img, n.X+layoutPadding, n.Y+layoutPadding+i*layoutLineHeight+2, line, c)
+		}
+	}
+
+	b := &bytes.Buffer{}
+
+	err := png.Encode(b, img)
+	if err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// getColor returns the color of a hex string, e.g. #4a6fa5
+func getColor(hex string) color.RGBA {
+	v, _ := strconv.ParseUint(hex[1:], 16, 32)
+
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
+
+// drawText draws the text with the bitmap font, from the top left corner
+func drawText(img *image.RGBA, x, y int, text string, c color.RGBA) {
+	for _, r := range text {
+		glyph := getGlyph(r)
+
+		for row := 0; row < fontGlyphHeight; row++ {
+			for col := 0; col < fontGlyphWidth; col++ {
+				if glyph[row]&(1<<(fontGlyphWidth-1-col)) == 0 {
+					continue
+				}
+
+				rect := image.Rect(x+col*pngFontScale, y+row*pngFontScale, x+(col+1)*pngFontScale, y+(row+1)*pngFontScale)
+				draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+			}
+		}
+
+		x = x + layoutCharWidth
+	}
+}
+
+// drawBorder draws the border of a box, dashed borders have 5 pixels long dashes
+func drawBorder(img *image.RGBA, x, y, width, height int, c color.RGBA, dashed bool) {
+	visible := func(p int) bool {
+		return !dashed || (p/5)%2 == 0
+	}
+
+	for i := 0; i < width; i++ {
+		if visible(i) {
+			img.SetRGBA(x+i, y, c)
+			img.SetRGBA(x+i, y+height-1, c)
+		}
+	}
+
+	for i := 0; i < height; i++ {
+		if visible(i) {
+			img.SetRGBA(x, y+i, c)
+			img.SetRGBA(x+width-1, y+i, c)
+		}
+	}
+}
+
+// drawLine draws a line 2 pixels wide with the Bresenham's algorithm
+func drawLine(img *image.RGBA, x1, y1, x2, y2 int, c color.RGBA) {
+	dx, dy := abs(x2-x1), -abs(y2-y1)
+	sx, sy := 1, 1
+
+	if x1 > x2 {
+		sx = -1
+	}
+	if y1 > y2 {
+		sy = -1
+	}
+
+	e := dx + dy
+
+	for {
+		img.SetRGBA(x1, y1, c)
+		img.SetRGBA(x1, y1+1, c)
+
+		if x1 == x2 && y1 == y2 {
+			return
+		}
+
+		e2 := 2 * e
+
+		if e2 >= dy {
+			e = e + dy
+			x1 = x1 + sx
+		}
+		if e2 <= dx {
+			e = e + dx
+			y1 = y1 + sy
+		}
+	}
+}
+
+// drawArrowHead draws a filled triangle at the end of the line, pointing in its direction
+func drawArrowHead(img *image.RGBA, x1, y1, x2, y2 int, c color.RGBA) {
+	const length, halfWidth = 10.0, 5.0
+
+	angle := math.Atan2(float64(y2-y1), float64(x2-x1))
+	tipX, tipY := float64(x2), float64(y2)
+	baseX, baseY := tipX-length*math.Cos(angle), tipY-length*math.Sin(angle)
+
+	ax, ay := baseX+halfWidth*math.Sin(angle), baseY-halfWidth*math.Cos(angle)
+	bx, by := baseX-halfWidth*math.Sin(angle), baseY+halfWidth*math.Cos(angle)
+
+	// The pixels are filled when they are on the same side of the three edges of the triangle
+	side := func(px, py, x1, y1, x2, y2 float64) float64 {
+		return (px-x2)*(y1-y2) - (x1-x2)*(py-y2)
+	}
+
+	minX, maxX := int(math.Min(tipX, math.Min(ax, bx))), int(math.Max(tipX, math.Max(ax, bx)))
+	minY, maxY := int(math.Min(tipY, math.Min(ay, by))), int(math.Max(tipY, math.Max(ay, by)))
+
+	for x := minX; x <= maxX; x++ {
+		for y := minY; y <= maxY; y++ {
+			px, py := float64(x), float64(y)
+			d1, d2, d3 := side(px, py, tipX, tipY, ax, ay), side(px, py, ax, ay, bx, by), side(px, py, bx, by, tipX, tipY)
+
+			negative := d1 < 0 || d2 < 0 || d3 < 0
+			positive := d1 > 0 || d2 > 0 || d3 > 0
+
+			if !(negative && positive) {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
+
+// abs returns the absolute value of the number
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+
+	return i
+}
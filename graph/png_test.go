@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestPrintImages(t *testing.T) {
+	s := NewFakeSource("default")
+	s.Forbidden = []string{"ingresses"}
+
+	b := NewBuilder(s, &bytes.Buffer{}, OutputTree, []string{"default"}, []Root{{Kind: "service", Name: "service-foo"}})
+
+	err := b.Collect()
+	if err != nil {
+		t.Fatalf("Graph could not be created. Error: %q", err)
+	}
+
+	l := NewLayout(NewGraph(b.ObjsData))
+
+	o := &bytes.Buffer{}
+
+	err = NewPrinter(b.ObjsData, OutputPNG, o).Print()
+	if err != nil {
+		t.Fatalf("Graph could not be printed as png. Error: %q", err)
+	}
+
+	img, err := png.Decode(o)
+	if err != nil {
+		t.Fatalf("PNG image could not be read. Error: %q", err)
+	}
+
+	if img.Bounds().Dx() != l.Width || img.Bounds().Dy() != l.Height {
+		t.Errorf("Returned PNG image size was incorrect, got: %dx%d, want: %dx%d", img.Bounds().Dx(), img.Bounds().Dy(), l.Width, l.Height)
+	}
+
+	o.Reset()
+
+	err = NewPrinter(b.ObjsData, OutputSVG, o).Print()
+	if err != nil {
+		t.Fatalf("Graph could not be printed as svg. Error: %q", err)
+	}
+
+	d := xml.NewDecoder(strings.NewReader(o.String()))
+	rects, lines := 0, 0
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			break
+		}
+
+		if e, ok := token.(xml.StartElement); ok {
+			switch e.Name.Local {
+			case "rect":
+				rects++
+			case "line":
+				lines++
+			}
+		}
+	}
+
+	// The background is a rect too
+	if rects != len(l.Nodes)+1 || lines != len(l.Edges) {
+		t.Errorf("Returned SVG image was incorrect, got: %d rects and %d lines, want: %d and %d", rects, lines, len(l.Nodes)+1, len(l.Edges))
+	}
+}
+
+func TestGetGlyph(t *testing.T) {
+	if getGlyph('A') != fontGlyphs['A'-' '] {
+		t.Errorf("Returned glyph of A was incorrect")
+	}
+
+	if getGlyph('é') != getGlyph('?') {
+		t.Errorf("Returned glyph of a character that is not printable ASCII was not a question mark")
+	}
+}
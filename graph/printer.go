@@ -1,33 +1,73 @@
 package graph
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/awalterschulze/gographviz"
+	"sigs.k8s.io/yaml"
 )
 
-// Printer holds the object data and graph type to print
+// Output formats of the graph
+const (
+	OutputTree = "tree"
+	OutputDot  = "dot"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+	// OutputWide prints the tree with the status columns of the objects
+	OutputWide = "wide"
+	// OutputMermaid prints a mermaid flowchart, rendered in Markdown by GitHub and GitLab
+	OutputMermaid = "mermaid"
+	// OutputPlantUML and OutputD2 print diagrams rendered by the PlantUML and D2 tools
+	OutputPlantUML = "plantuml"
+	OutputD2       = "d2"
+	// OutputGraphML and OutputCytoscape export the graph to graph analysis tools
+	OutputGraphML   = "graphml"
+	OutputCytoscape = "cytoscape"
+	// OutputHTML prints an interactive HTML page that can be opened offline
+	OutputHTML = "html"
+	// OutputSVG and OutputPNG print images drawn without Graphviz
+	OutputSVG = "svg"
+	OutputPNG = "png"
+	// OutputJSONLines prints the changes of the graph in watch mode
+	OutputJSONLines = "jsonl"
+)
+
+// Outputs holds the output formats supported by the Printer
+var Outputs = []string{OutputTree, OutputWide, OutputDot, OutputJSON, OutputYAML, OutputMermaid, OutputPlantUML, OutputD2, OutputGraphML, OutputCytoscape, OutputHTML, OutputSVG, OutputPNG}
+
+// Printer holds the objects data and output format to print
 type Printer struct {
-	ObjData
-	DotGraph bool
+	ObjsData []ObjData
+	Output   string
 	Out      io.Writer
+	// Color adds the ANSI colored health indicators of the objects to the tree and wide outputs
+	Color bool
 }
 
 // NewPrinter returns a new Printer struct
-func NewPrinter(objData ObjData, dotGraph bool, out io.Writer) *Printer {
+func NewPrinter(objsData []ObjData, output string, out io.Writer) *Printer {
 	return &Printer{
-		ObjData:  objData,
-		DotGraph: dotGraph,
+		ObjsData: objsData,
+		Output:   output,
 		Out:      out,
 	}
 }
 
-// Print prints the tree or dot graph
+// Print prints the graph in the output format: a tree, a wide tree with status
+// columns, a dot graph, a mermaid flowchart, a PlantUML or D2 diagram, or the nodes
+// and edges of the graph as JSON, YAML, GraphML or Cytoscape.js JSON, an interactive
+// HTML page, or an SVG or PNG image. The tree groups the objects per namespace when they belong to
+// more than one namespace, the dot graph adds them to the cluster of their namespace.
 func (p *Printer) Print() (err error) {
 	g := ""
 
-	if p.DotGraph {
+	switch p.Output {
+	case OutputDot:
 		gv := gographviz.NewGraph()
 
 		err := gv.SetName("W")
@@ -45,14 +85,79 @@ func (p *Printer) Print() (err error) {
 			return err
 		}
 
-		_, err = createDotGraph(p.ObjData, gv)
+		for _, ns := range getNamespaces(p.ObjsData) {
+			err := gv.AddSubGraph("W", getDotClusterName(ns), map[string]string{"label": quoteDotID(ns)})
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, o := range p.ObjsData {
+			_, err = createDotGraph(o, gv)
+			if err != nil {
+				return err
+			}
+		}
+
+		g = gv.String()
+	case OutputJSON:
+		b, err := json.MarshalIndent(NewGraph(p.ObjsData), "", "  ")
 		if err != nil {
 			return err
 		}
 
-		g = gv.String()
-	} else {
-		g = fmt.Sprintf("\n%s\n\n", createTreeGraph(p.ObjData, "", ""))
+		g = string(b) + "\n"
+	case OutputYAML:
+		b, err := yaml.Marshal(NewGraph(p.ObjsData))
+		if err != nil {
+			return err
+		}
+
+		g = string(b)
+	case OutputMermaid:
+		g = createMermaidGraph(p.ObjsData)
+	case OutputPlantUML:
+		g = createPlantUMLGraph(p.ObjsData)
+	case OutputD2:
+		g = createD2Graph(p.ObjsData)
+	case OutputGraphML:
+		g, err = createGraphMLGraph(p.ObjsData)
+		if err != nil {
+			return err
+		}
+	case OutputCytoscape:
+		g, err = createCytoscapeGraph(p.ObjsData)
+		if err != nil {
+			return err
+		}
+	case OutputHTML:
+		g, err = createHTMLGraph(p.ObjsData)
+		if err != nil {
+			return err
+		}
+	case OutputSVG:
+		g = createSVGGraph(p.ObjsData)
+	case OutputPNG:
+		g, err = createPNGGraph(p.ObjsData)
+		if err != nil {
+			return err
+		}
+	case OutputWide:
+		namespaces, groups := groupByNamespace(p.ObjsData)
+		g = createWideTreeGraph(namespaces, groups, getExpandedIDs(p.ObjsData), time.Now(), p.Color)
+	default:
+		namespaces, groups := groupByNamespace(p.ObjsData)
+		expanded := getExpandedIDs(p.ObjsData)
+
+		for _, ns := range namespaces {
+			if len(namespaces) > 1 {
+				g = g + fmt.Sprintf("\nNamespace: %s\n", ns)
+			}
+
+			for _, o := range groups[ns] {
+				g = g + fmt.Sprintf("\n%s\n\n", createTreeGraph(o, expanded, p.Color))
+			}
+		}
 	}
 
 	fmt.Fprint(p.Out, g)
@@ -60,54 +165,146 @@ func (p *Printer) Print() (err error) {
 	return
 }
 
-// createTreeGraph returns a string holding the tree graph
-func createTreeGraph(o ObjData, graph, format string) string {
+// groupByNamespace returns the sorted namespaces and the objects data grouped by their namespace
+func groupByNamespace(objsData []ObjData) ([]string, map[string][]ObjData) {
+	namespaces := []string{}
+	groups := map[string][]ObjData{}
 
-	if graph == "" {
-		graph = fmt.Sprintf("[%s] %s", o.Obj.GetKind(), o.Obj.GetName())
-	} else if o.Hierarchy == "upper" {
-		graph = fmt.Sprintf("%s┌── [%s] %s", format, o.Obj.GetKind(), o.Obj.GetName())
-	} else if o.Hierarchy == "lower" {
-		graph = fmt.Sprintf("%s└── [%s] %s", format, o.Obj.GetKind(), o.Obj.GetName())
+	for _, o := range objsData {
+		ns := o.Obj.GetNamespace()
+		if !Contains(ns, namespaces) {
+			namespaces = append(namespaces, ns)
+		}
+		groups[ns] = append(groups[ns], o)
 	}
+	sort.Strings(namespaces)
 
-	format = format + "\t"
+	return namespaces, groups
+}
+
+// getNamespaces returns the sorted namespaces of all the objects and related objects
+func getNamespaces(objsData []ObjData) []string {
+	namespaces := []string{}
+
+	for _, o := range objsData {
+		for _, ns := range append(getNamespaces(o.RelatedObjsData), o.Obj.GetNamespace()) {
+			if ns != "" && !Contains(ns, namespaces) {
+				namespaces = append(namespaces, ns)
+			}
+		}
+	}
+	sort.Strings(namespaces)
+
+	return namespaces
+}
+
+// createTreeGraph returns a string holding the tree graph, with the health
+// indicators of the objects when color is true
+func createTreeGraph(o ObjData, expanded map[string]bool, color bool) string {
+	lines := []string{}
+	for _, l := range createTreeLines(o, "", expanded) {
+		lines = append(lines, getTreeLineText(l, color))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// TreeLine holds a line of the tree graph: the indentation and branch, the kind
+// and name of the object, and the object data it shows
+type TreeLine struct {
+	Prefix  string
+	Name    string
+	ObjData ObjData
+}
+
+// createTreeLines returns the lines of the tree graph. The upper related objects
+// are printed above the object and the lower related objects below it. The objects
+// whose related objects are printed in another line of the graph are marked as deduped.
+func createTreeLines(o ObjData, format string, expanded map[string]bool) []TreeLine {
+	prefix := ""
+	if format != "" && o.Hierarchy == "upper" {
+		prefix = format + "┌── "
+	} else if format != "" && o.Hierarchy == "lower" {
+		prefix = format + "└── "
+	}
+
+	name := fmt.Sprintf("[%s] %s", o.Obj.GetKind(), getObjName(o))
+	if len(o.RelatedObjsData) == 0 && expanded[getObjDataID(o)] {
+		name = name + " (deduped)"
+	}
+
+	lines := []TreeLine{{Prefix: prefix, Name: name, ObjData: o}}
 
 	for _, r := range o.RelatedObjsData {
-		relatedGraph := createTreeGraph(r, graph, format)
+		relatedLines := createTreeLines(r, format+"\t", expanded)
 
 		if r.Hierarchy == "upper" {
-			graph = relatedGraph + "\n" + graph
+			lines = append(relatedLines, lines...)
 		} else {
-			graph = graph + "\n" + relatedGraph
+			lines = append(lines, relatedLines...)
 		}
 	}
 
-	return graph
+	return lines
 }
 
-// createDotGraph returns a string holding the dot graph
+// getExpandedIDs returns the IDs of the objects printed with their related objects
+func getExpandedIDs(objsData []ObjData) map[string]bool {
+	ids := map[string]bool{}
+
+	var add func(o ObjData)
+	add = func(o ObjData) {
+		if len(o.RelatedObjsData) > 0 {
+			ids[getObjDataID(o)] = true
+		}
+
+		for _, r := range o.RelatedObjsData {
+			add(r)
+		}
+	}
+
+	for _, o := range objsData {
+		add(o)
+	}
+
+	return ids
+}
+
+// createDotGraph returns a string holding the dot graph. The nodes are added to the
+// subgraph of their namespace, and are styled by their kind and health. The edges
+// are styled by their relation.
 func createDotGraph(o ObjData, g *gographviz.Graph) (string, error) {
-	err := g.AddNode("W", GetPrettyString(o.Obj.GetKind()+o.Obj.GetName()), map[string]string{"label": "\"" + o.Obj.GetKind() + ": " + o.Obj.GetName() + "\""})
+	id := getDotNodeID(o)
+
+	parentGraph, err := getDotParentGraph(o, g)
+	if err != nil {
+		return "", err
+	}
+
+	err = g.AddNode(parentGraph, id, getDotNodeAttrs(o))
 	if err != nil {
 		return "", err
 	}
 
+	f := NewFilter()
+
 	for _, r := range o.RelatedObjsData {
 		n, err := createDotGraph(r, g)
 		if err != nil {
 			return "", err
 		}
 
+		attrs := getDotEdgeAttrs(f.GetRelation(o.Obj.GetKind(), r.Obj.GetKind()))
+
 		if r.Hierarchy == "upper" {
-			err := g.AddEdge(n, GetPrettyString(o.Obj.GetKind()+o.Obj.GetName()), true, nil)
+			err := g.AddEdge(n, id, true, attrs)
 			if err != nil {
 				return "", err
 			}
 		}
 
 		if r.Hierarchy == "lower" {
-			err := g.AddEdge(GetPrettyString(o.Obj.GetKind()+o.Obj.GetName()), n, true, nil)
+			err := g.AddEdge(id, n, true, attrs)
 			if err != nil {
 				return "", err
 			}
@@ -115,5 +312,127 @@ func createDotGraph(o ObjData, g *gographviz.Graph) (string, error) {
 
 	}
 
-	return GetPrettyString(o.Obj.GetKind() + o.Obj.GetName()), nil
+	return id, nil
+}
+
+// getDotParentGraph returns the subgraph where the node of the object is added: the cluster
+// of its namespace, or a subgraph in it that places the ingresses at the top and the pods
+// at the bottom of the cluster. The ranks are per namespace because dot does not rank
+// the nodes of different clusters together.
+func getDotParentGraph(o ObjData, g *gographviz.Graph) (string, error) {
+	parentGraph, parentName := "W", "W"
+	if o.Obj.GetNamespace() != "" {
+		parentGraph, parentName = getDotClusterName(o.Obj.GetNamespace()), "cluster_"+o.Obj.GetNamespace()
+	}
+
+	rank := ""
+
+	switch strings.ToLower(o.Obj.GetKind()) {
+	case "ingress":
+		rank = "min"
+	case "pod":
+		rank = "max"
+	default:
+		return parentGraph, nil
+	}
+
+	rankGraph := quoteDotID("rank_" + rank + "_" + parentName)
+
+	if !g.IsSubGraph(rankGraph) {
+		err := g.AddSubGraph(parentGraph, rankGraph, map[string]string{"rank": rank})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return rankGraph, nil
+}
+
+// getDotNodeAttrs returns the shape and color of the kind of the object, and the fill color of its health
+func getDotNodeAttrs(o ObjData) map[string]string {
+	shape, color := "box", "#424242"
+
+	switch strings.ToLower(o.Obj.GetKind()) {
+	case "ingress":
+		shape, color = "invtrapezium", "#6a1b9a"
+	case "service":
+		shape, color = "hexagon", "#1565c0"
+	case "deployment":
+		shape, color = "box3d", "#2e7d32"
+	case "statefulset":
+		shape, color = "cylinder", "#00838f"
+	case "daemonset":
+		shape, color = "component", "#ef6c00"
+	case "replicaset":
+		shape, color = "folder", "#558b2f"
+	case "pod":
+		shape, color = "ellipse", "#424242"
+	}
+
+	fillColor := "#ffffff"
+
+	switch getObjHealth(o) {
+	case HealthHealthy:
+		fillColor = "#c8e6c9"
+	case HealthProgressing:
+		fillColor = "#fff9c4"
+	case HealthUnhealthy:
+		fillColor = "#ffcdd2"
+	}
+
+	style := "filled"
+	if o.Unknown != "" {
+		style = "filled,dashed"
+	}
+
+	return map[string]string{
+		"label":     quoteDotID(o.Obj.GetKind() + ": " + getObjName(o)),
+		"shape":     shape,
+		"color":     "\"" + color + "\"",
+		"fillcolor": "\"" + fillColor + "\"",
+		"style":     "\"" + style + "\"",
+	}
+}
+
+// getDotEdgeAttrs returns the style of the edge of a relation: solid for the owner
+// references, dashed for the label selectors, dotted for the references by name
+// and bold for the external names
+func getDotEdgeAttrs(relation string) map[string]string {
+	switch relation {
+	case "ownerReference":
+		return map[string]string{"style": "solid"}
+	case "labelSelector":
+		return map[string]string{"style": "dashed"}
+	case "ingressBackend":
+		return map[string]string{"style": "dotted"}
+	case "externalName":
+		return map[string]string{"style": "bold"}
+	}
+
+	return nil
+}
+
+// getObjName returns the object name or the reason why the object is unknown
+func getObjName(o ObjData) string {
+	if o.Unknown != "" {
+		return fmt.Sprintf("unknown (%s)", o.Unknown)
+	}
+
+	return o.Obj.GetName()
+}
+
+// getDotClusterName returns the name of the dot subgraph of a namespace
+func getDotClusterName(namespace string) string {
+	return quoteDotID("cluster_" + namespace)
+}
+
+// getDotNodeID returns the ID of the object node in the dot graph: its kind and group,
+// namespace and name, e.g. "deployment.apps/default/foo", so two objects never share a node
+func getDotNodeID(o ObjData) string {
+	return quoteDotID(getObjDataID(o))
+}
+
+// quoteDotID returns the string as a quoted dot ID, with the quotes and backslashes escaped
+func quoteDotID(s string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(s) + "\""
 }
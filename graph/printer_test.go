@@ -2,8 +2,10 @@ package graph
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
@@ -37,6 +39,7 @@ func TestPrint(t *testing.T) {
 						},
 						"upper",
 						[]ObjData{},
+						"",
 					},
 					{
 						unstructured.Unstructured{
@@ -49,16 +52,28 @@ func TestPrint(t *testing.T) {
 						},
 						"lower",
 						[]ObjData{},
+						"",
 					},
 				},
+				"",
 			},
 			"\n\t┌── [Ingress] ingress-foo\n[Service] service-foo\n\t└── [Pod] pod-foo\n\n",
 			`strict digraph W {
-	Ingressingressfoo->Serviceservicefoo;
-	Serviceservicefoo->Podpodfoo;
-	Ingressingressfoo [ label="Ingress: ingress-foo" ];
-	Podpodfoo [ label="Pod: pod-foo" ];
-	Serviceservicefoo [ label="Service: service-foo" ];
+	"ingress//ingress-foo"->"service//service-foo"[ style=dotted ];
+	"service//service-foo"->"pod//pod-foo"[ style=dashed ];
+	subgraph "rank_max_W" {
+	rank=max;
+	"pod//pod-foo" [ color="#424242", fillcolor="#ffffff", label="Pod: pod-foo", shape=ellipse, style="filled" ];
+
+}
+;
+	subgraph "rank_min_W" {
+	rank=min;
+	"ingress//ingress-foo" [ color="#6a1b9a", fillcolor="#ffffff", label="Ingress: ingress-foo", shape=invtrapezium, style="filled" ];
+
+}
+;
+	"service//service-foo" [ color="#1565c0", fillcolor="#ffffff", label="Service: service-foo", shape=hexagon, style="filled" ];
 
 }
 `,
@@ -67,7 +82,7 @@ func TestPrint(t *testing.T) {
 
 	for _, test := range tests {
 		resultTreeGraph := &bytes.Buffer{}
-		p1 := NewPrinter(test.ObjData, false, resultTreeGraph)
+		p1 := NewPrinter([]ObjData{test.ObjData}, OutputTree, resultTreeGraph)
 		p1.Print()
 
 		if resultTreeGraph.String() != test.ExpectedTreeGraph {
@@ -75,7 +90,7 @@ func TestPrint(t *testing.T) {
 		}
 
 		resultDotGraph := &bytes.Buffer{}
-		p2 := NewPrinter(test.ObjData, true, resultDotGraph)
+		p2 := NewPrinter([]ObjData{test.ObjData}, OutputDot, resultDotGraph)
 		p2.Print()
 
 		if resultDotGraph.String() != test.ExpectedDotGraph {
@@ -83,3 +98,692 @@ func TestPrint(t *testing.T) {
 		}
 	}
 }
+
+func TestPrintNamespaces(t *testing.T) {
+	objsData := []ObjData{}
+
+	for _, ns := range []string{"default", "staging"} {
+		objsData = append(objsData, ObjData{
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"kind": "Service",
+					"metadata": map[string]interface{}{
+						"name":      "service-" + ns,
+						"namespace": ns,
+					},
+				},
+			},
+			"",
+			[]ObjData{},
+			"",
+		})
+	}
+
+	expectedTreeGraph := "\nNamespace: default\n\n[Service] service-default\n\n\nNamespace: staging\n\n[Service] service-staging\n\n"
+	expectedDotGraph := `strict digraph W {
+	subgraph "cluster_default" {
+	label="default";
+	"service/default/service-default" [ color="#1565c0", fillcolor="#ffffff", label="Service: service-default", shape=hexagon, style="filled" ];
+
+}
+;
+	subgraph "cluster_staging" {
+	label="staging";
+	"service/staging/service-staging" [ color="#1565c0", fillcolor="#ffffff", label="Service: service-staging", shape=hexagon, style="filled" ];
+
+}
+;
+
+}
+`
+
+	resultTreeGraph := &bytes.Buffer{}
+	p1 := NewPrinter(objsData, OutputTree, resultTreeGraph)
+	p1.Print()
+
+	if resultTreeGraph.String() != expectedTreeGraph {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", resultTreeGraph.String(), expectedTreeGraph)
+	}
+
+	resultDotGraph := &bytes.Buffer{}
+	p2 := NewPrinter(objsData, OutputDot, resultDotGraph)
+	p2.Print()
+
+	if resultDotGraph.String() != expectedDotGraph {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", resultDotGraph.String(), expectedDotGraph)
+	}
+}
+
+func TestPrintDotStyles(t *testing.T) {
+	objData := ObjData{
+		Obj: unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"kind": "ReplicaSet",
+				"metadata": map[string]interface{}{
+					"name":      "replicaset-foo",
+					"namespace": "default",
+				},
+				"spec":   map[string]interface{}{"replicas": int64(2)},
+				"status": map[string]interface{}{"readyReplicas": int64(1)},
+			},
+		},
+		RelatedObjsData: []ObjData{
+			{
+				Obj: unstructured.Unstructured{
+					Object: map[string]interface{}{
+						"kind": "Pod",
+						"metadata": map[string]interface{}{
+							"name":      "pod-foo",
+							"namespace": "default",
+						},
+						"status": map[string]interface{}{"phase": "Failed"},
+					},
+				},
+				Hierarchy: "lower",
+			},
+		},
+	}
+
+	expectedDotGraph := `strict digraph W {
+	"replicaset/default/replicaset-foo"->"pod/default/pod-foo"[ style=solid ];
+	subgraph "cluster_default" {
+	label="default";
+	subgraph "rank_max_cluster_default" {
+	rank=max;
+	"pod/default/pod-foo" [ color="#424242", fillcolor="#ffcdd2", label="Pod: pod-foo", shape=ellipse, style="filled" ];
+
+}
+;
+	"replicaset/default/replicaset-foo" [ color="#558b2f", fillcolor="#fff9c4", label="ReplicaSet: replicaset-foo", shape=folder, style="filled" ];
+
+}
+;
+
+}
+`
+
+	resultDotGraph := &bytes.Buffer{}
+
+	err := NewPrinter([]ObjData{objData}, OutputDot, resultDotGraph).Print()
+	if err != nil {
+		t.Fatalf("Graph could not be printed. Error: %q", err)
+	}
+
+	if resultDotGraph.String() != expectedDotGraph {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", resultDotGraph.String(), expectedDotGraph)
+	}
+}
+
+func TestPrintDotNamespaces(t *testing.T) {
+	objData := ObjData{
+		Obj: newTestObj("v1", "Service", "shop", "db", nil),
+		RelatedObjsData: []ObjData{
+			{Obj: newTestObj("networking.k8s.io/v1", "Ingress", "shop", "web", nil), Hierarchy: "upper"},
+			{
+				Obj:       newTestObj("v1", "Service", "data", "db", nil),
+				Hierarchy: "lower",
+				RelatedObjsData: []ObjData{
+					{Obj: newTestObj("v1", "Pod", "data", "db-0", nil), Hierarchy: "lower"},
+				},
+			},
+		},
+	}
+
+	// The ingresses and pods are ranked in the cluster of their namespace
+	expectedDotGraph := `strict digraph W {
+	"ingress.networking.k8s.io/shop/web"->"service/shop/db"[ style=dotted ];
+	"service/data/db"->"pod/data/db-0"[ style=dashed ];
+	"service/shop/db"->"service/data/db"[ style=bold ];
+	subgraph "cluster_data" {
+	label="data";
+	subgraph "rank_max_cluster_data" {
+	rank=max;
+	"pod/data/db-0" [ color="#424242", fillcolor="#ffffff", label="Pod: db-0", shape=ellipse, style="filled" ];
+
+}
+;
+	"service/data/db" [ color="#1565c0", fillcolor="#ffffff", label="Service: db", shape=hexagon, style="filled" ];
+
+}
+;
+	subgraph "cluster_shop" {
+	label="shop";
+	subgraph "rank_min_cluster_shop" {
+	rank=min;
+	"ingress.networking.k8s.io/shop/web" [ color="#6a1b9a", fillcolor="#ffffff", label="Ingress: web", shape=invtrapezium, style="filled" ];
+
+}
+;
+	"service/shop/db" [ color="#1565c0", fillcolor="#ffffff", label="Service: db", shape=hexagon, style="filled" ];
+
+}
+;
+
+}
+`
+
+	resultDotGraph := &bytes.Buffer{}
+
+	err := NewPrinter([]ObjData{objData}, OutputDot, resultDotGraph).Print()
+	if err != nil {
+		t.Fatalf("Graph could not be printed. Error: %q", err)
+	}
+
+	if resultDotGraph.String() != expectedDotGraph {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", resultDotGraph.String(), expectedDotGraph)
+	}
+}
+
+func TestPrintDotIDs(t *testing.T) {
+	objsData := []ObjData{}
+
+	for _, o := range []struct{ Namespace, Name string }{
+		{"default", "foo-bar"},
+		{"default", "foobar"},
+		{"staging", "foobar"},
+		{"default", "foo.bar"},
+	} {
+		objsData = append(objsData, ObjData{
+			Obj: unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "apps/v1",
+					"kind":       "Deployment",
+					"metadata": map[string]interface{}{
+						"name":      o.Name,
+						"namespace": o.Namespace,
+					},
+				},
+			},
+		})
+	}
+
+	resultDotGraph := &bytes.Buffer{}
+
+	err := NewPrinter(objsData, OutputDot, resultDotGraph).Print()
+	if err != nil {
+		t.Fatalf("Graph could not be printed. Error: %q", err)
+	}
+
+	for _, id := range []string{`"deployment.apps/default/foo-bar"`, `"deployment.apps/default/foobar"`, `"deployment.apps/staging/foobar"`, `"deployment.apps/default/foo.bar"`} {
+		if strings.Count(resultDotGraph.String(), id+" [") != 1 {
+			t.Errorf("Returned graph does not hold one node %s,\ngot:\n%s", id, resultDotGraph.String())
+		}
+	}
+}
+
+func TestQuoteDotID(t *testing.T) {
+	tests := []struct {
+		ID         string
+		ExpectedID string
+	}{
+		{"pod/default/foo", `"pod/default/foo"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`back\slash`, `"back\\slash"`},
+	}
+
+	for _, test := range tests {
+		id := quoteDotID(test.ID)
+
+		if id != test.ExpectedID {
+			t.Errorf("Returned ID was incorrect, got: %s, want: %s", id, test.ExpectedID)
+		}
+	}
+}
+
+func TestPrintFormats(t *testing.T) {
+	pod := newTestObj("v1", "Pod", "", "web-0", nil)
+	pod.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-1"}})
+
+	tests := []struct {
+		ObjsData          []ObjData
+		ExpectedMermaid   string
+		ExpectedPlantUML  string
+		ExpectedD2        string
+		ExpectedCytoscape string
+		ExpectedHTMLGraph string
+	}{
+		// The edges of every relation, an edge without relation and an unknown object
+		{
+			[]ObjData{
+				{
+					Obj: newTestObj("v1", "Service", "", "web", nil),
+					RelatedObjsData: []ObjData{
+						{Obj: newTestObj("networking.k8s.io/v1", "Ingress", "", "web", nil), Hierarchy: "upper"},
+						newUnknownObjData("ingress", "", "upper", "forbidden"),
+						{
+							Obj:       pod,
+							Hierarchy: "lower",
+							RelatedObjsData: []ObjData{
+								{Obj: newTestObj("apps/v1", "ReplicaSet", "", "web-1", nil), Hierarchy: "upper"},
+							},
+						},
+						{Obj: newTestObj("v1", "Service", "", "db", nil), Hierarchy: "lower"},
+						{Obj: newTestObj("v1", "ConfigMap", "", "web-config", nil), Hierarchy: "lower"},
+					},
+				},
+			},
+			`flowchart LR
+    n0{{"Service: web"}}
+    n1(["Ingress: web"])
+    n2(["Ingress: unknown (forbidden)"])
+    n3("Pod: web-0")
+    n4[/"ReplicaSet: web-1"/]
+    n5{{"Service: db"}}
+    n6["ConfigMap: web-config"]
+    n1 -->|ingressBackend| n0
+    n2 -->|ingressBackend| n0
+    n0 -->|labelSelector| n3
+    n4 -->|ownerReference| n3
+    n0 -->|externalName| n5
+    n0 --> n6
+    classDef unknown stroke-dasharray: 5 5
+    class n2 unknown
+`,
+			`@startuml
+left to right direction
+interface "Service: web" <<Service>> as n0
+boundary "Ingress: web" <<Ingress>> as n1
+boundary "Ingress: unknown (forbidden)" <<Ingress>> as n2 #line.dashed
+node "Pod: web-0" <<Pod>> as n3
+collections "ReplicaSet: web-1" <<ReplicaSet>> as n4
+interface "Service: db" <<Service>> as n5
+rectangle "ConfigMap: web-config" <<ConfigMap>> as n6
+n1 --> n0 : ingressBackend
+n2 --> n0 : ingressBackend
+n0 --> n3 : labelSelector
+n4 --> n3 : ownerReference
+n0 --> n5 : externalName
+n0 --> n6
+@enduml
+`,
+			`direction: right
+n0: "Service: web" {
+  shape: hexagon
+}
+n1: "Ingress: web" {
+  shape: cloud
+}
+n2: "Ingress: unknown (forbidden)" {
+  shape: cloud
+  style.stroke-dash: 5
+}
+n3: "Pod: web-0" {
+  shape: oval
+}
+n4: "ReplicaSet: web-1" {
+  shape: parallelogram
+}
+n5: "Service: db" {
+  shape: hexagon
+}
+n6: "ConfigMap: web-config" {
+  shape: rectangle
+}
+n1 -> n0: ingressBackend
+n2 -> n0: ingressBackend
+n0 -> n3: labelSelector
+n4 -> n3: ownerReference
+n0 -> n5: externalName
+n0 -> n6
+`,
+			`{
+  "elements": {
+    "nodes": [
+      {
+        "data": {
+          "id": "service//web",
+          "label": "Service: web",
+          "apiVersion": "v1",
+          "kind": "Service",
+          "namespace": "",
+          "name": "web",
+          "uid": "",
+          "labels": {},
+          "status": ""
+        }
+      },
+      {
+        "data": {
+          "id": "ingress.networking.k8s.io//web",
+          "label": "Ingress: web",
+          "apiVersion": "networking.k8s.io/v1",
+          "kind": "Ingress",
+          "namespace": "",
+          "name": "web",
+          "uid": "",
+          "labels": {},
+          "status": ""
+        }
+      },
+      {
+        "data": {
+          "id": "ingress//(forbidden)",
+          "label": "Ingress: unknown (forbidden)",
+          "apiVersion": "",
+          "kind": "Ingress",
+          "namespace": "",
+          "name": "",
+          "uid": "",
+          "labels": {},
+          "status": "",
+          "unknown": "forbidden"
+        }
+      },
+      {
+        "data": {
+          "id": "pod//web-0",
+          "label": "Pod: web-0",
+          "apiVersion": "v1",
+          "kind": "Pod",
+          "namespace": "",
+          "name": "web-0",
+          "uid": "",
+          "labels": {},
+          "status": ""
+        }
+      },
+      {
+        "data": {
+          "id": "replicaset.apps//web-1",
+          "label": "ReplicaSet: web-1",
+          "apiVersion": "apps/v1",
+          "kind": "ReplicaSet",
+          "namespace": "",
+          "name": "web-1",
+          "uid": "",
+          "labels": {},
+          "status": ""
+        }
+      },
+      {
+        "data": {
+          "id": "service//db",
+          "label": "Service: db",
+          "apiVersion": "v1",
+          "kind": "Service",
+          "namespace": "",
+          "name": "db",
+          "uid": "",
+          "labels": {},
+          "status": ""
+        }
+      },
+      {
+        "data": {
+          "id": "configmap//web-config",
+          "label": "ConfigMap: web-config",
+          "apiVersion": "v1",
+          "kind": "ConfigMap",
+          "namespace": "",
+          "name": "web-config",
+          "uid": "",
+          "labels": {},
+          "status": ""
+        }
+      }
+    ],
+    "edges": [
+      {
+        "data": {
+          "id": "e0",
+          "source": "ingress.networking.k8s.io//web",
+          "target": "service//web",
+          "relation": "ingressBackend"
+        }
+      },
+      {
+        "data": {
+          "id": "e1",
+          "source": "ingress//(forbidden)",
+          "target": "service//web",
+          "relation": "ingressBackend"
+        }
+      },
+      {
+        "data": {
+          "id": "e2",
+          "source": "service//web",
+          "target": "pod//web-0",
+          "relation": "labelSelector"
+        }
+      },
+      {
+        "data": {
+          "id": "e3",
+          "source": "replicaset.apps//web-1",
+          "target": "pod//web-0",
+          "relation": "ownerReference"
+        }
+      },
+      {
+        "data": {
+          "id": "e4",
+          "source": "service//web",
+          "target": "service//db",
+          "relation": "externalName"
+        }
+      },
+      {
+        "data": {
+          "id": "e5",
+          "source": "service//web",
+          "target": "configmap//web-config",
+          "relation": ""
+        }
+      }
+    ]
+  }
+}
+`,
+			`{"nodes":[{"id":"service//web","apiVersion":"v1","kind":"Service","namespace":"","name":"web","uid":"","status":"","label":"Service: web","column":1,"ownerReferences":[],"fields":[]},{"id":"ingress.networking.k8s.io//web","apiVersion":"networking.k8s.io/v1","kind":"Ingress","namespace":"","name":"web","uid":"","status":"","label":"Ingress: web","column":0,"ownerReferences":[],"fields":[]},{"id":"ingress//(forbidden)","apiVersion":"","kind":"Ingress","namespace":"","name":"","uid":"","status":"","unknown":"forbidden","label":"Ingress: unknown (forbidden)","column":0,"ownerReferences":[],"fields":[]},{"id":"pod//web-0","apiVersion":"v1","kind":"Pod","namespace":"","name":"web-0","uid":"","status":"","label":"Pod: web-0","column":3,"ownerReferences":["ReplicaSet/web-1"],"fields":[]},{"id":"replicaset.apps//web-1","apiVersion":"apps/v1","kind":"ReplicaSet","namespace":"","name":"web-1","uid":"","status":"","label":"ReplicaSet: web-1","column":2,"ownerReferences":[],"fields":[]},{"id":"service//db","apiVersion":"v1","kind":"Service","namespace":"","name":"db","uid":"","status":"","label":"Service: db","column":1,"ownerReferences":[],"fields":[]},{"id":"configmap//web-config","apiVersion":"v1","kind":"ConfigMap","namespace":"","name":"web-config","uid":"","status":"","label":"ConfigMap: web-config","column":4,"ownerReferences":[],"fields":[]}],"edges":[{"source":"ingress.networking.k8s.io//web","target":"service//web","relation":"ingressBackend"},{"source":"ingress//(forbidden)","target":"service//web","relation":"ingressBackend"},{"source":"service//web","target":"pod//web-0","relation":"labelSelector"},{"source":"replicaset.apps//web-1","target":"pod//web-0","relation":"ownerReference"},{"source":"service//web","target":"service//db","relation":"externalName"},{"source":"service//web","target":"configmap//web-config","relation":""}]}`,
+		},
+		// The objects of two namespaces related across them
+		{
+			[]ObjData{
+				{
+					Obj: newTestObj("v1", "Service", "team-a", "web", nil),
+					RelatedObjsData: []ObjData{
+						{Obj: newTestObj("v1", "Service", "team-b", "db", nil), Hierarchy: "lower"},
+					},
+				},
+			},
+			`flowchart LR
+    subgraph ns_team_a["team-a"]
+        n0{{"Service: web"}}
+    end
+    subgraph ns_team_b["team-b"]
+        n1{{"Service: db"}}
+    end
+    n0 -->|externalName| n1
+`,
+			`@startuml
+left to right direction
+frame "team-a" as ns0 {
+  interface "Service: web" <<Service>> as n0
+}
+frame "team-b" as ns1 {
+  interface "Service: db" <<Service>> as n1
+}
+n0 --> n1 : externalName
+@enduml
+`,
+			`direction: right
+ns0: "team-a" {
+  n0: "Service: web" {
+    shape: hexagon
+  }
+}
+ns1: "team-b" {
+  n1: "Service: db" {
+    shape: hexagon
+  }
+}
+ns0.n0 -> ns1.n1: externalName
+`,
+			`{
+  "elements": {
+    "nodes": [
+      {
+        "data": {
+          "id": "service/team-a/web",
+          "label": "Service: web",
+          "apiVersion": "v1",
+          "kind": "Service",
+          "namespace": "team-a",
+          "name": "web",
+          "uid": "",
+          "labels": {},
+          "status": ""
+        }
+      },
+      {
+        "data": {
+          "id": "service/team-b/db",
+          "label": "Service: db",
+          "apiVersion": "v1",
+          "kind": "Service",
+          "namespace": "team-b",
+          "name": "db",
+          "uid": "",
+          "labels": {},
+          "status": ""
+        }
+      }
+    ],
+    "edges": [
+      {
+        "data": {
+          "id": "e0",
+          "source": "service/team-a/web",
+          "target": "service/team-b/db",
+          "relation": "externalName"
+        }
+      }
+    ]
+  }
+}
+`,
+			`{"nodes":[{"id":"service/team-a/web","apiVersion":"v1","kind":"Service","namespace":"team-a","name":"web","uid":"","status":"","label":"Service: web","column":0,"ownerReferences":[],"fields":[]},{"id":"service/team-b/db","apiVersion":"v1","kind":"Service","namespace":"team-b","name":"db","uid":"","status":"","label":"Service: db","column":0,"ownerReferences":[],"fields":[]}],"edges":[{"source":"service/team-a/web","target":"service/team-b/db","relation":"externalName"}]}`,
+		},
+		// The names with the characters that end a quoted label or string
+		{
+			[]ObjData{
+				{
+					Obj: newTestObj("v1", "Service", "default", `say "hi"`, nil),
+					RelatedObjsData: []ObjData{
+						{Obj: newTestObj("v1", "Pod", "default", `back\slash <b>`, nil), Hierarchy: "lower"},
+					},
+				},
+			},
+			`flowchart LR
+    subgraph ns_default["default"]
+        n0{{"Service: say #quot;hi#quot;"}}
+        n1("Pod: back\slash #lt;b#gt;")
+    end
+    n0 -->|labelSelector| n1
+`,
+			`@startuml
+left to right direction
+frame "default" as ns0 {
+  interface "Service: say &#34;hi&#34;" <<Service>> as n0
+  node "Pod: back&#92;slash &#60;b&#62;" <<Pod>> as n1
+}
+n0 --> n1 : labelSelector
+@enduml
+`,
+			`direction: right
+ns0: "default" {
+  n0: "Service: say \"hi\"" {
+    shape: hexagon
+  }
+  n1: "Pod: back\\slash <b>" {
+    shape: oval
+  }
+}
+ns0.n0 -> ns0.n1: labelSelector
+`,
+			`{
+  "elements": {
+    "nodes": [
+      {
+        "data": {
+          "id": "service/default/say \"hi\"",
+          "label": "Service: say \"hi\"",
+          "apiVersion": "v1",
+          "kind": "Service",
+          "namespace": "default",
+          "name": "say \"hi\"",
+          "uid": "",
+          "labels": {},
+          "status": ""
+        }
+      },
+      {
+        "data": {
+          "id": "pod/default/back\\slash \u003cb\u003e",
+          "label": "Pod: back\\slash \u003cb\u003e",
+          "apiVersion": "v1",
+          "kind": "Pod",
+          "namespace": "default",
+          "name": "back\\slash \u003cb\u003e",
+          "uid": "",
+          "labels": {},
+          "status": ""
+        }
+      }
+    ],
+    "edges": [
+      {
+        "data": {
+          "id": "e0",
+          "source": "service/default/say \"hi\"",
+          "target": "pod/default/back\\slash \u003cb\u003e",
+          "relation": "labelSelector"
+        }
+      }
+    ]
+  }
+}
+`,
+			`{"nodes":[{"id":"service/default/say \"hi\"","apiVersion":"v1","kind":"Service","namespace":"default","name":"say \"hi\"","uid":"","status":"","label":"Service: say \"hi\"","column":0,"ownerReferences":[],"fields":[]},{"id":"pod/default/back\\slash \u003cb\u003e","apiVersion":"v1","kind":"Pod","namespace":"default","name":"back\\slash \u003cb\u003e","uid":"","status":"","label":"Pod: back\\slash \u003cb\u003e","column":1,"ownerReferences":[],"fields":[]}],"edges":[{"source":"service/default/say \"hi\"","target":"pod/default/back\\slash \u003cb\u003e","relation":"labelSelector"}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		for output, expected := range map[string]string{
+			OutputMermaid:   test.ExpectedMermaid,
+			OutputPlantUML:  test.ExpectedPlantUML,
+			OutputD2:        test.ExpectedD2,
+			OutputCytoscape: test.ExpectedCytoscape,
+		} {
+			result := &bytes.Buffer{}
+
+			err := NewPrinter(test.ObjsData, output, result).Print()
+			if err != nil {
+				t.Fatalf("Graph could not be printed. Error: %q", err)
+			}
+
+			if result.String() != expected {
+				t.Errorf("Returned %s graph was incorrect,\ngot:\n%s\nwant:\n%s", output, result.String(), expected)
+			}
+		}
+
+		page := &bytes.Buffer{}
+
+		err := NewPrinter(test.ObjsData, OutputHTML, page).Print()
+		if err != nil {
+			t.Fatalf("Page could not be printed. Error: %q", err)
+		}
+
+		if !strings.Contains(page.String(), "var graph = "+test.ExpectedHTMLGraph+";") {
+			t.Errorf("Returned page does not hold the graph,\ngot:\n%s\nwant:\n%s", page.String(), test.ExpectedHTMLGraph)
+		}
+
+		// The page must be opened offline
+		for _, external := range []string{`src="http`, `href="http`, "@import"} {
+			if strings.Contains(page.String(), external) {
+				t.Errorf("Returned page loads an external resource: %s", external)
+			}
+		}
+	}
+}
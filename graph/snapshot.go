@@ -0,0 +1,250 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+)
+
+// Snapshot holds the objects and edges of a graph, so the graph can be saved
+// and printed later without a cluster
+type Snapshot struct {
+	Objects  []SnapshotObj  `json:"objects"`
+	Edges    []SnapshotEdge `json:"edges"`
+	Roots    []string       `json:"roots"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+// SnapshotObj holds an object of the graph and its ID. Unknown holds
+// the reason why the related objects of a kind could not be listed.
+type SnapshotObj struct {
+	ID      string                 `json:"id"`
+	Unknown string                 `json:"unknown,omitempty"`
+	Object  map[string]interface{} `json:"object"`
+}
+
+// UnmarshalJSON decodes the object with the unstructured JSON decoder,
+// so the numbers are int64 as the status and wide columns read them
+func (o *SnapshotObj) UnmarshalJSON(b []byte) error {
+	type snapshotObj SnapshotObj
+
+	data := struct {
+		*snapshotObj
+		Object json.RawMessage `json:"object"`
+	}{
+		snapshotObj: (*snapshotObj)(o),
+	}
+
+	err := json.Unmarshal(b, &data)
+	if err != nil {
+		return err
+	}
+
+	obj := &unstructured.Unstructured{}
+
+	err = obj.UnmarshalJSON(data.Object)
+	if err != nil {
+		return fmt.Errorf("object '%s' can not be read: %v", o.ID, err)
+	}
+
+	o.Object = obj.Object
+
+	return nil
+}
+
+// SnapshotEdge holds the relation between two objects in the graph of a root.
+// Hierarchy is upper if To is printed above From, or lower if it is below.
+type SnapshotEdge struct {
+	Root      string `json:"root"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Hierarchy string `json:"hierarchy"`
+}
+
+// NewSnapshot returns a new Snapshot struct holding the objects data and warnings
+func NewSnapshot(objsData []ObjData, warnings []string) *Snapshot {
+	s := &Snapshot{
+		Objects:  []SnapshotObj{},
+		Edges:    []SnapshotEdge{},
+		Roots:    []string{},
+		Warnings: warnings,
+	}
+
+	ids := map[string]bool{}
+	edges := map[SnapshotEdge]bool{}
+
+	for _, o := range objsData {
+		root := getObjDataID(o)
+		s.Roots = append(s.Roots, root)
+		s.addObjData(root, o, ids, edges)
+	}
+
+	return s
+}
+
+// addObjData adds the object and its related objects with their edges to the snapshot.
+// An edge is added once per root even if the object is found more than once in its graph.
+func (s *Snapshot) addObjData(root string, o ObjData, ids map[string]bool, edges map[SnapshotEdge]bool) {
+	id := getObjDataID(o)
+
+	if !ids[id] {
+		ids[id] = true
+		s.Objects = append(s.Objects, SnapshotObj{
+			ID:      id,
+			Unknown: o.Unknown,
+			Object:  o.Obj.Object,
+		})
+	}
+
+	for _, r := range o.RelatedObjsData {
+		e := SnapshotEdge{
+			Root:      root,
+			From:      id,
+			To:        getObjDataID(r),
+			Hierarchy: r.Hierarchy,
+		}
+
+		if !edges[e] {
+			edges[e] = true
+			s.Edges = append(s.Edges, e)
+		}
+
+		s.addObjData(root, r, ids, edges)
+	}
+}
+
+// GetObjsData returns the objects data rebuilt from the objects and edges of the snapshot
+func (s *Snapshot) GetObjsData() ([]ObjData, error) {
+	objs := map[string]SnapshotObj{}
+	for _, o := range s.Objects {
+		objs[o.ID] = o
+	}
+
+	objsData := []ObjData{}
+
+	for _, root := range s.Roots {
+		o, err := s.getObjData(root, objs)
+		if err != nil {
+			return objsData, err
+		}
+
+		objsData = append(objsData, o)
+	}
+
+	return objsData, nil
+}
+
+// getObjData returns the object data of the root with the related objects of its edges.
+// The tree is built breadth first and each object is expanded once, where it is closest
+// to the root, like the merged graphs of the Builder.
+func (s *Snapshot) getObjData(root string, objs map[string]SnapshotObj) (ObjData, error) {
+	edges := map[string][]SnapshotEdge{}
+	for _, e := range s.Edges {
+		if e.Root == root {
+			edges[e.From] = append(edges[e.From], e)
+		}
+	}
+
+	newNode := func(id, hierarchy string) (*mergeNode, error) {
+		o, ok := objs[id]
+		if !ok {
+			return nil, fmt.Errorf("object '%s' not found in the snapshot", id)
+		}
+
+		return &mergeNode{
+			ObjData: ObjData{
+				Obj:       unstructured.Unstructured{Object: o.Object},
+				Hierarchy: hierarchy,
+				Unknown:   o.Unknown,
+			},
+			ID: id,
+		}, nil
+	}
+
+	rootNode, err := newNode(root, "")
+	if err != nil {
+		return ObjData{}, err
+	}
+
+	expanded := map[string]bool{root: true}
+	queue := []*mergeNode{rootNode}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		for _, e := range edges[n.ID] {
+			child, err := newNode(e.To, e.Hierarchy)
+			if err != nil {
+				return ObjData{}, err
+			}
+
+			n.Children = append(n.Children, child)
+
+			if !expanded[e.To] {
+				expanded[e.To] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	return getMergedObjData(rootNode), nil
+}
+
+// NewSnapshotSource returns a source holding the objects of the snapshot, to build
+// the graph of other roots from them without a cluster. Unknown objects are skipped.
+func NewSnapshotSource(s *Snapshot) *ObjectSource {
+	objs := []unstructured.Unstructured{}
+
+	for _, o := range s.Objects {
+		if o.Unknown == "" {
+			objs = append(objs, unstructured.Unstructured{Object: o.Object})
+		}
+	}
+
+	return NewObjectSource(objs)
+}
+
+// Save writes the snapshot as JSON to the file
+func (s *Snapshot) Save(filename string) error {
+	klog.V(1).Infof("save snapshot to %s", filename)
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, append(b, '\n'), 0644)
+}
+
+// LoadSnapshot returns the snapshot saved in the file
+func LoadSnapshot(filename string) (*Snapshot, error) {
+	klog.V(1).Infof("load snapshot from %s", filename)
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{}
+
+	err = json.Unmarshal(b, s)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot '%s' can not be read: %v", filename, err)
+	}
+
+	return s, nil
+}
+
+// getObjDataID returns the ID of the object in the snapshot and in the nodes
+// of the graph. Unknown objects have no name, so the reason is used instead.
+func getObjDataID(o ObjData) string {
+	if o.Unknown != "" {
+		return GetObjID(o.Obj) + "(" + o.Unknown + ")"
+	}
+
+	return GetObjID(o.Obj)
+}
@@ -0,0 +1,237 @@
+package graph
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSnapshot(t *testing.T) {
+	s := NewFakeSource("default")
+	s.Forbidden = []string{"ingresses"}
+
+	o := &bytes.Buffer{}
+
+	roots := []Root{
+		{Kind: "statefulset", Name: "statefulset-foo-2"},
+		{Kind: "service", Name: "service-foo"},
+		{Kind: "pod", Name: "pod-foo-2"},
+	}
+
+	b := NewBuilder(s, o, OutputTree, []string{"default"}, roots)
+
+	err := b.Build()
+	if err != nil {
+		t.Fatalf("Graph could not be created. Error: %q", err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "snapshot.json")
+
+	err = NewSnapshot(b.ObjsData, b.Warnings).Save(filename)
+	if err != nil {
+		t.Fatalf("Snapshot could not be saved. Error: %q", err)
+	}
+
+	snapshot, err := LoadSnapshot(filename)
+	if err != nil {
+		t.Fatalf("Snapshot could not be loaded. Error: %q", err)
+	}
+
+	objsData, err := snapshot.GetObjsData()
+	if err != nil {
+		t.Fatalf("Snapshot objects could not be read. Error: %q", err)
+	}
+
+	for _, output := range []string{OutputTree, OutputWide, OutputDot, OutputJSON} {
+		expected := &bytes.Buffer{}
+		got := &bytes.Buffer{}
+
+		err = NewPrinter(b.ObjsData, output, expected).Print()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = NewPrinter(objsData, output, got).Print()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.String() != expected.String() {
+			t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", got.String(), expected.String())
+		}
+	}
+
+	if !reflect.DeepEqual(snapshot.Warnings, b.Warnings) {
+		t.Errorf("Returned warnings were incorrect, got: %v want: %v", snapshot.Warnings, b.Warnings)
+	}
+}
+
+func TestSnapshotStatus(t *testing.T) {
+	manifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment-foo
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: foo
+status:
+  readyReplicas: 3
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: service-foo
+spec:
+  selector:
+    app: foo
+  ports:
+  - port: 80
+    protocol: TCP
+`
+
+	objs, err := loadManifestData([]byte(manifest), "foo.yaml", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBuilder(NewObjectSource(objs), &bytes.Buffer{}, OutputTree, []string{"default"}, []Root{{Kind: "deployment", Name: "deployment-foo"}, {Kind: "service", Name: "service-foo"}})
+
+	err = b.Build()
+	if err != nil {
+		t.Fatalf("Graph could not be created. Error: %q", err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "snapshot.json")
+
+	err = NewSnapshot(b.ObjsData, b.Warnings).Save(filename)
+	if err != nil {
+		t.Fatalf("Snapshot could not be saved. Error: %q", err)
+	}
+
+	snapshot, err := LoadSnapshot(filename)
+	if err != nil {
+		t.Fatalf("Snapshot could not be loaded. Error: %q", err)
+	}
+
+	objsData, err := snapshot.GetObjsData()
+	if err != nil {
+		t.Fatalf("Snapshot objects could not be read. Error: %q", err)
+	}
+
+	o := &bytes.Buffer{}
+
+	err = NewPrinter(objsData, OutputWide, o).Print()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The replicas and ports are read as int64 numbers from the snapshot
+	expected := "\nNAME                          READY   STATUS   RESTARTS   AGE   NODE   TYPE   PORTS    HOSTS\n\n[Deployment] deployment-foo   3/3\n\n\n[Service] service-foo                                                         80/TCP\n\n"
+
+	if o.String() != expected {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", o.String(), expected)
+	}
+}
+
+func TestSnapshotSource(t *testing.T) {
+	s := NewFakeSource("default")
+
+	b := NewBuilder(s, &bytes.Buffer{}, OutputTree, []string{"default"}, []Root{{Kind: "service", Name: "service-foo"}})
+
+	err := b.Build()
+	if err != nil {
+		t.Fatalf("Graph could not be created. Error: %q", err)
+	}
+
+	// The graph of a pod of the service is built from the objects of the snapshot
+	snapshotSource := NewSnapshotSource(NewSnapshot(b.ObjsData, b.Warnings))
+
+	roots := []Root{{Kind: "pod", Name: "pod-foo-1"}}
+
+	expected := &bytes.Buffer{}
+	got := &bytes.Buffer{}
+
+	err = NewBuilder(s, expected, OutputTree, []string{"default"}, roots).Build()
+	if err != nil {
+		t.Fatalf("Graph could not be created. Error: %q", err)
+	}
+
+	err = NewBuilder(snapshotSource, got, OutputTree, snapshotSource.GetNamespaces(), roots).Build()
+	if err != nil {
+		t.Fatalf("Graph could not be created from the snapshot. Error: %q", err)
+	}
+
+	if got.String() != expected.String() {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", got.String(), expected.String())
+	}
+}
+
+func TestSnapshotSharedObjects(t *testing.T) {
+	replicaSet := newTestObj("apps/v1", "ReplicaSet", "default", "web-1", nil)
+	replicaSet.SetUID("1")
+
+	pods := []unstructured.Unstructured{
+		newTestObj("v1", "Pod", "default", "web-1-a", map[string]string{"app": "web"}),
+		newTestObj("v1", "Pod", "default", "web-1-b", map[string]string{"app": "web", "tier": "b"}),
+	}
+	for i := range pods {
+		pods[i].SetOwnerReferences([]metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-1", UID: "1"}})
+	}
+
+	web := newTestObj("v1", "Service", "default", "web", nil)
+	web.Object["spec"] = map[string]interface{}{"selector": map[string]interface{}{"app": "web"}}
+
+	tierB := newTestObj("v1", "Service", "default", "tier-b", nil)
+	tierB.Object["spec"] = map[string]interface{}{"selector": map[string]interface{}{"tier": "b"}}
+
+	s := NewObjectSource([]unstructured.Unstructured{replicaSet, pods[0], pods[1], web, tierB})
+
+	o := &bytes.Buffer{}
+
+	// The pods share the replicaset and the service, so the pod web-1-b is found twice
+	// in the graph of the pod web-1-a and is expanded once
+	b := NewBuilder(s, o, OutputTree, []string{"default"}, []Root{{Kind: "pod", Name: "web-1-a"}, {Kind: "pod", Name: "web-1-b"}})
+
+	err := b.Build()
+	if err != nil {
+		t.Fatalf("Graph could not be created. Error: %q", err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "snapshot.json")
+
+	err = NewSnapshot(b.ObjsData, b.Warnings).Save(filename)
+	if err != nil {
+		t.Fatalf("Snapshot could not be saved. Error: %q", err)
+	}
+
+	snapshot, err := LoadSnapshot(filename)
+	if err != nil {
+		t.Fatalf("Snapshot could not be loaded. Error: %q", err)
+	}
+
+	objsData, err := snapshot.GetObjsData()
+	if err != nil {
+		t.Fatalf("Snapshot objects could not be read. Error: %q", err)
+	}
+
+	if ToJSON(objsData) != ToJSON(b.ObjsData) {
+		t.Errorf("Returned objects data were incorrect,\ngot:\n%s\nwant:\n%s", ToJSON(objsData), ToJSON(b.ObjsData))
+	}
+
+	got := &bytes.Buffer{}
+
+	err = NewPrinter(objsData, OutputTree, got).Print()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.String() != o.String() {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", got.String(), o.String())
+	}
+}
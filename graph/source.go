@@ -0,0 +1,260 @@
+package graph
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/metadata"
+	"k8s.io/klog/v2"
+)
+
+// Source gets and lists the objects used to build the graph.
+// An empty namespace means all namespaces.
+type Source interface {
+	Get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error)
+	List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error)
+}
+
+// DynamicSource gets the objects from a cluster with the dynamic client
+type DynamicSource struct {
+	Client dynamic.Interface
+}
+
+// NewDynamicSource returns a new DynamicSource struct
+func NewDynamicSource(client dynamic.Interface) *DynamicSource {
+	return &DynamicSource{
+		Client: client,
+	}
+}
+
+// Get returns an object from the cluster
+func (s *DynamicSource) Get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	return s.Client.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// List returns a list of objects from the cluster
+func (s *DynamicSource) List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	return s.Client.Resource(gvr).Namespace(namespace).List(ctx, opts)
+}
+
+// MetadataSource gets only the metadata of the objects from a cluster with the metadata client
+type MetadataSource struct {
+	Client metadata.Interface
+}
+
+// NewMetadataSource returns a new MetadataSource struct
+func NewMetadataSource(client metadata.Interface) *MetadataSource {
+	return &MetadataSource{
+		Client: client,
+	}
+}
+
+// Get returns the metadata of an object from the cluster
+func (s *MetadataSource) Get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	m, err := s.Client.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return toUnstructured(m, gvr)
+}
+
+// List returns a list with the metadata of the objects from the cluster
+func (s *MetadataSource) List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	l, err := s.Client.Resource(gvr).Namespace(namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	objList := &unstructured.UnstructuredList{}
+	objList.SetContinue(l.GetContinue())
+
+	for i := range l.Items {
+		o, err := toUnstructured(&l.Items[i], gvr)
+		if err != nil {
+			return nil, err
+		}
+
+		objList.Items = append(objList.Items, *o)
+	}
+
+	return objList, nil
+}
+
+// toUnstructured converts a partial object metadata to an unstructured object.
+// The API server returns the objects as PartialObjectMetadata, so the real
+// apiVersion and kind are set to let the Filter relate them.
+func toUnstructured(m *metav1.PartialObjectMetadata, gvr schema.GroupVersionResource) (*unstructured.Unstructured, error) {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(m)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &unstructured.Unstructured{Object: u}
+	o.SetAPIVersion(gvr.GroupVersion().String())
+	o.SetKind(getKindName(gvr.Resource))
+
+	return o, nil
+}
+
+// ObjectSource holds the objects in memory, e.g. the objects of manifests or snapshots.
+// The objects are matched by group and resource, so any version of a kind is served.
+type ObjectSource struct {
+	Objs []unstructured.Unstructured
+}
+
+// NewObjectSource returns a new ObjectSource struct
+func NewObjectSource(objs []unstructured.Unstructured) *ObjectSource {
+	return &ObjectSource{
+		Objs: objs,
+	}
+}
+
+// Get returns an object
+func (s *ObjectSource) Get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	for _, o := range s.Objs {
+		if isResource(o, gvr) && o.GetNamespace() == namespace && o.GetName() == name {
+			return o.DeepCopy(), nil
+		}
+	}
+
+	return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+}
+
+// GetNamespaces returns the sorted namespaces of the objects
+func (s *ObjectSource) GetNamespaces() []string {
+	namespaces := []string{}
+
+	for _, o := range s.Objs {
+		if o.GetNamespace() != "" && !Contains(o.GetNamespace(), namespaces) {
+			namespaces = append(namespaces, o.GetNamespace())
+		}
+	}
+	sort.Strings(namespaces)
+
+	return namespaces
+}
+
+// List returns a list of the objects that match the label and field selectors.
+// The metadata.name and metadata.namespace fields are supported, and the
+// continue token is the index of the next object.
+func (s *ObjectSource) List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	labelSelector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+
+	fieldSelector, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+
+	objs := []unstructured.Unstructured{}
+
+	for _, o := range s.Objs {
+		if !isResource(o, gvr) || (namespace != metav1.NamespaceAll && o.GetNamespace() != namespace) {
+			continue
+		}
+
+		if !labelSelector.Matches(labels.Set(o.GetLabels())) {
+			continue
+		}
+
+		if !fieldSelector.Matches(fields.Set{"metadata.name": o.GetName(), "metadata.namespace": o.GetNamespace()}) {
+			continue
+		}
+
+		objs = append(objs, *o.DeepCopy())
+	}
+
+	sort.SliceStable(objs, func(i, j int) bool {
+		return objs[i].GetNamespace() < objs[j].GetNamespace()
+	})
+
+	start := 0
+	if opts.Continue != "" {
+		start, err = strconv.Atoi(opts.Continue)
+		if err != nil || start > len(objs) {
+			return nil, apierrors.NewBadRequest("continue token is not valid")
+		}
+	}
+
+	objList := &unstructured.UnstructuredList{}
+	end := len(objs)
+
+	if opts.Limit > 0 && start+int(opts.Limit) < end {
+		end = start + int(opts.Limit)
+		objList.SetContinue(strconv.Itoa(end))
+	}
+
+	objList.Items = objs[start:end]
+
+	return objList, nil
+}
+
+// listChunks lists the objects of a group version resource in chunks of chunkSize objects,
+// or at once when chunkSize is 0, and returns only the ones accepted by the keep function
+func listChunks(s Source, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions, chunkSize int64, keep func(unstructured.Unstructured) bool) ([]unstructured.Unstructured, error) {
+	objs := []unstructured.Unstructured{}
+	opts.Limit = chunkSize
+
+	klog.V(2).Infof("list '%s' with label selector '%s' and field selector '%s'", gvr.Resource, opts.LabelSelector, opts.FieldSelector)
+
+	for {
+		objList, err := s.List(context.TODO(), gvr, namespace, opts)
+		if err != nil {
+			return objs, err
+		}
+
+		for _, o := range objList.Items {
+			if keep(o) {
+				objs = append(objs, o)
+			}
+		}
+
+		opts.Continue = objList.GetContinue()
+		if opts.Continue == "" {
+			break
+		}
+
+		klog.V(2).Infof("list next chunk of '%s'", gvr.Resource)
+	}
+
+	return objs, nil
+}
+
+// isResource returns true if the object kind belongs to the group and resource.
+// The kinds of the legacy extensions group, e.g. extensions/v1beta1 ingresses,
+// belong to the groups they moved to.
+func isResource(o unstructured.Unstructured, gvr schema.GroupVersionResource) bool {
+	plural, _ := meta.UnsafeGuessKindToResource(o.GroupVersionKind())
+
+	return getGroupResource(plural) == gvr.GroupResource()
+}
+
+// getGroupResource returns the group and resource of the version resource,
+// with the group the kinds of the extensions group moved to
+func getGroupResource(gvr schema.GroupVersionResource) schema.GroupResource {
+	if gvr.Group != "extensions" {
+		return gvr.GroupResource()
+	}
+
+	switch gvr.Resource {
+	case "ingresses":
+		return schema.GroupResource{Group: "networking.k8s.io", Resource: gvr.Resource}
+	case "deployments", "replicasets", "daemonsets":
+		return schema.GroupResource{Group: "apps", Resource: gvr.Resource}
+	}
+
+	return gvr.GroupResource()
+}
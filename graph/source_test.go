@@ -0,0 +1,183 @@
+package graph
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/metadata/fake"
+)
+
+func newTestObj(apiVersion, kind, namespace, name string, labels map[string]string) unstructured.Unstructured {
+	o := unstructured.Unstructured{Object: map[string]interface{}{}}
+	o.SetAPIVersion(apiVersion)
+	o.SetKind(kind)
+	o.SetNamespace(namespace)
+	o.SetName(name)
+	o.SetLabels(labels)
+
+	return o
+}
+
+func TestObjectSourceGet(t *testing.T) {
+	s := NewObjectSource([]unstructured.Unstructured{
+		newTestObj("v1", "Pod", "default", "pod-foo", nil),
+		newTestObj("networking.k8s.io/v1", "Ingress", "default", "ingress-foo", nil),
+		newTestObj("extensions/v1beta1", "Ingress", "default", "ingress-bar", nil),
+		newTestObj("extensions/v1beta1", "Deployment", "default", "deployment-foo", nil),
+		newTestObj("apps/v1beta2", "Deployment", "default", "deployment-bar", nil),
+		newTestObj("example.com/v1", "Service", "default", "service-foo", nil),
+	})
+
+	tests := []struct {
+		Kind      string
+		Namespace string
+		Name      string
+		NotFound  bool
+	}{
+		{"pod", "default", "pod-foo", false},
+		{"pod", "kube-system", "pod-foo", true},
+		{"service", "default", "pod-foo", true},
+		// The ingress version of the object differs from the version of the resource
+		{"ingress", "default", "ingress-foo", false},
+		// The kinds of the legacy groups belong to the groups they moved to
+		{"ingress", "default", "ingress-bar", false},
+		{"deployment", "default", "deployment-foo", false},
+		{"deployment", "default", "deployment-bar", false},
+		// A kind of another group is not a supported resource
+		{"service", "default", "service-foo", true},
+	}
+
+	for _, test := range tests {
+		gvr, _ := getGroupVersionResource(test.Kind)
+
+		o, err := s.Get(context.TODO(), gvr, test.Namespace, test.Name)
+		if test.NotFound {
+			if !apierrors.IsNotFound(err) {
+				t.Errorf("Returned error was incorrect for %s %s/%s, got: %v want: not found", test.Kind, test.Namespace, test.Name, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Object could not be found. Error: %q", err)
+			continue
+		}
+
+		if o.GetName() != test.Name {
+			t.Errorf("Returned result was incorrect, got: %s want: %s", o.GetName(), test.Name)
+		}
+	}
+}
+
+func TestObjectSourceList(t *testing.T) {
+	s := NewObjectSource([]unstructured.Unstructured{
+		newTestObj("v1", "Pod", "foo", "pod-foo-1", map[string]string{"app": "foo"}),
+		newTestObj("v1", "Pod", "bar", "pod-bar-1", map[string]string{"app": "bar"}),
+		newTestObj("v1", "Pod", "foo", "pod-foo-2", map[string]string{"app": "foo"}),
+		newTestObj("v1", "Service", "foo", "service-foo", nil),
+	})
+
+	gvr, _ := getGroupVersionResource("pod")
+
+	tests := []struct {
+		Namespace string
+		Opts      metav1.ListOptions
+		Expected  []string
+	}{
+		{
+			"foo",
+			metav1.ListOptions{},
+			[]string{"pod-foo-1", "pod-foo-2"},
+		},
+		{
+			metav1.NamespaceAll,
+			metav1.ListOptions{},
+			[]string{"pod-bar-1", "pod-foo-1", "pod-foo-2"},
+		},
+		{
+			metav1.NamespaceAll,
+			metav1.ListOptions{LabelSelector: "app=foo"},
+			[]string{"pod-foo-1", "pod-foo-2"},
+		},
+		{
+			metav1.NamespaceAll,
+			metav1.ListOptions{FieldSelector: "metadata.name=pod-bar-1"},
+			[]string{"pod-bar-1"},
+		},
+		{
+			metav1.NamespaceAll,
+			metav1.ListOptions{FieldSelector: "metadata.namespace=foo"},
+			[]string{"pod-foo-1", "pod-foo-2"},
+		},
+	}
+
+	for _, test := range tests {
+		for _, limit := range []int64{0, 1, 2} {
+			opts := test.Opts
+			opts.Limit = limit
+
+			names := []string{}
+
+			for {
+				l, err := s.List(context.TODO(), gvr, test.Namespace, opts)
+				if err != nil {
+					t.Fatalf("Objects could not be listed. Error: %q", err)
+				}
+
+				for _, o := range l.Items {
+					names = append(names, o.GetName())
+				}
+
+				if l.GetContinue() == "" {
+					break
+				}
+				opts.Continue = l.GetContinue()
+			}
+
+			if !reflect.DeepEqual(names, test.Expected) {
+				t.Errorf("Returned result was incorrect for %+v, got: %v want: %v", opts, names, test.Expected)
+			}
+		}
+	}
+}
+
+func TestMetadataSourceList(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = metav1.AddMetaToScheme(scheme)
+
+	m := &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apps/v1",
+			Kind:       "StatefulSet",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "statefulset-foo",
+		},
+	}
+
+	s := NewMetadataSource(fake.NewSimpleMetadataClient(scheme, m))
+
+	gvr, _ := getGroupVersionResource("statefulset")
+
+	l, err := s.List(context.TODO(), gvr, "default", metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Objects could not be listed. Error: %q", err)
+	}
+
+	if len(l.Items) != 1 {
+		t.Fatalf("Returned result was incorrect, got: %d objects want: 1", len(l.Items))
+	}
+
+	got := []string{l.Items[0].GetAPIVersion(), l.Items[0].GetKind(), l.Items[0].GetName()}
+	expected := []string{"apps/v1", "StatefulSet", "statefulset-foo"}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Returned result was incorrect, got: %v want: %v", got, expected)
+	}
+}
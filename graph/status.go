@@ -0,0 +1,218 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// getObjStatus returns a short status of the object: the phase of a pod, the
+// ready and desired replicas of a controller, the type of a service or the load
+// balancer address of an ingress. Objects without status return an empty string.
+func getObjStatus(o ObjData) string {
+	if o.Unknown != "" {
+		return ""
+	}
+
+	obj := o.Obj
+
+	if obj.GetDeletionTimestamp() != nil {
+		return "Terminating"
+	}
+
+	switch strings.ToLower(obj.GetKind()) {
+	case "pod":
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		return phase
+	case "deployment", "replicaset", "statefulset", "daemonset":
+		if ready, desired, ok := getReadyReplicas(obj); ok {
+			return fmt.Sprintf("%d/%d ready", ready, desired)
+		}
+	case "service":
+		serviceType, _, _ := unstructured.NestedString(obj.Object, "spec", "type")
+		return serviceType
+	case "ingress":
+		ingresses, _, _ := unstructured.NestedSlice(obj.Object, "status", "loadBalancer", "ingress")
+		addresses := []string{}
+
+		for _, i := range ingresses {
+			if m, ok := i.(map[string]interface{}); ok {
+				for _, f := range []string{"ip", "hostname"} {
+					if a, ok := m[f].(string); ok && a != "" {
+						addresses = append(addresses, a)
+					}
+				}
+			}
+		}
+
+		return strings.Join(addresses, ",")
+	}
+
+	return ""
+}
+
+// getReadyReplicas returns the ready and desired replicas of a controller. It returns
+// false for other kinds and for the objects without status, e.g. read from manifests.
+func getReadyReplicas(obj unstructured.Unstructured) (int64, int64, bool) {
+	if _, found := obj.Object["status"]; !found {
+		return 0, 0, false
+	}
+
+	var ready, desired int64
+
+	switch strings.ToLower(obj.GetKind()) {
+	case "deployment", "replicaset", "statefulset":
+		// The API server defaults the replicas to 1
+		replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if !found {
+			replicas = 1
+		}
+
+		desired = replicas
+		ready, _, _ = unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+	case "daemonset":
+		desired, _, _ = unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+		ready, _, _ = unstructured.NestedInt64(obj.Object, "status", "numberReady")
+	default:
+		return 0, 0, false
+	}
+
+	return ready, desired, true
+}
+
+// Health of the objects
+const (
+	HealthHealthy     = "healthy"
+	HealthProgressing = "progressing"
+	HealthUnhealthy   = "unhealthy"
+)
+
+// failingContainerReasons holds the reasons of the waiting containers that
+// do not start until the pod or its environment is fixed
+var failingContainerReasons = []string{"CrashLoopBackOff", "ImagePullBackOff", "ErrImagePull", "CreateContainerConfigError", "CreateContainerError", "InvalidImageName", "RunContainerError"}
+
+// getObjHealth returns the health of the pods, controllers and services: healthy when a pod
+// is ready or succeeded, all the replicas are ready or all the pods of a service are ready,
+// unhealthy when a pod failed or its containers can not start, or no replica or pod of a
+// service is ready, and progressing otherwise. Other objects return an empty string.
+func getObjHealth(o ObjData) string {
+	if o.Unknown != "" {
+		return ""
+	}
+
+	obj := o.Obj
+
+	if obj.GetDeletionTimestamp() != nil {
+		return HealthProgressing
+	}
+
+	switch strings.ToLower(obj.GetKind()) {
+	case "pod":
+		return getPodHealth(obj)
+	case "service":
+		return getServiceHealth(o)
+	}
+
+	ready, desired, ok := getReadyReplicas(obj)
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case ready >= desired:
+		return HealthHealthy
+	case ready == 0:
+		return HealthUnhealthy
+	}
+
+	return HealthProgressing
+}
+
+// getPodHealth returns the health of the pod from its phase, the waiting reason of its
+// containers, e.g. CrashLoopBackOff, and its Ready condition
+func getPodHealth(obj unstructured.Unstructured) string {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+
+	switch phase {
+	case "":
+		return ""
+	case "Succeeded":
+		return HealthHealthy
+	}
+
+	containerStatuses, _, _ := unstructured.NestedSlice(obj.Object, "status", "containerStatuses")
+	for _, c := range containerStatuses {
+		cs, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		reason, _, _ := unstructured.NestedString(cs, "state", "waiting", "reason")
+		if Contains(reason, failingContainerReasons) {
+			return HealthUnhealthy
+		}
+	}
+
+	switch phase {
+	case "Running":
+		if isPodReady(obj) {
+			return HealthHealthy
+		}
+
+		return HealthProgressing
+	case "Pending":
+		return HealthProgressing
+	}
+
+	return HealthUnhealthy
+}
+
+// isPodReady returns false when the Ready condition of the pod is not true.
+// The pods without conditions are ready, e.g. read from a snapshot.
+func isPodReady(obj unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+
+		return condition["status"] == "True"
+	}
+
+	return true
+}
+
+// getServiceHealth returns the health of the service from its lower related pods,
+// the endpoints of the service. It is empty when none of the pods has a status.
+func getServiceHealth(o ObjData) string {
+	pods, ready := 0, 0
+
+	for _, r := range o.RelatedObjsData {
+		if r.Hierarchy != "lower" || strings.ToLower(r.Obj.GetKind()) != "pod" {
+			continue
+		}
+
+		switch getObjHealth(r) {
+		case "":
+			continue
+		case HealthHealthy:
+			ready++
+		}
+
+		pods++
+	}
+
+	switch {
+	case pods == 0:
+		return ""
+	case ready == pods:
+		return HealthHealthy
+	case ready == 0:
+		return HealthUnhealthy
+	}
+
+	return HealthProgressing
+}
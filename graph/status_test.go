@@ -0,0 +1,169 @@
+package graph
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetObjStatus(t *testing.T) {
+	tests := []struct {
+		ObjData        ObjData
+		ExpectedStatus string
+	}{
+		{
+			ObjData{Obj: unstructured.Unstructured{Object: map[string]interface{}{
+				"kind":   "Pod",
+				"status": map[string]interface{}{"phase": "Running"},
+			}}},
+			"Running",
+		},
+		{
+			ObjData{Obj: unstructured.Unstructured{Object: map[string]interface{}{
+				"kind":     "Pod",
+				"metadata": map[string]interface{}{"deletionTimestamp": "2021-08-01T10:00:00Z"},
+				"status":   map[string]interface{}{"phase": "Running"},
+			}}},
+			"Terminating",
+		},
+		{
+			ObjData{Obj: unstructured.Unstructured{Object: map[string]interface{}{
+				"kind":   "Deployment",
+				"spec":   map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{"readyReplicas": int64(2)},
+			}}},
+			"2/3 ready",
+		},
+		{
+			ObjData{Obj: unstructured.Unstructured{Object: map[string]interface{}{
+				"kind":   "StatefulSet",
+				"status": map[string]interface{}{},
+			}}},
+			"0/1 ready",
+		},
+		{
+			ObjData{Obj: unstructured.Unstructured{Object: map[string]interface{}{
+				"kind": "ReplicaSet",
+				"spec": map[string]interface{}{"replicas": int64(3)},
+			}}},
+			"",
+		},
+		{
+			ObjData{Obj: unstructured.Unstructured{Object: map[string]interface{}{
+				"kind":   "DaemonSet",
+				"status": map[string]interface{}{"desiredNumberScheduled": int64(4), "numberReady": int64(4)},
+			}}},
+			"4/4 ready",
+		},
+		{
+			ObjData{Obj: unstructured.Unstructured{Object: map[string]interface{}{
+				"kind": "Service",
+				"spec": map[string]interface{}{"type": "ClusterIP"},
+			}}},
+			"ClusterIP",
+		},
+		{
+			ObjData{Obj: unstructured.Unstructured{Object: map[string]interface{}{
+				"kind": "Ingress",
+				"status": map[string]interface{}{"loadBalancer": map[string]interface{}{"ingress": []interface{}{
+					map[string]interface{}{"ip": "10.0.0.1"},
+					map[string]interface{}{"hostname": "foo.example.com"},
+				}}},
+			}}},
+			"10.0.0.1,foo.example.com",
+		},
+		{
+			ObjData{Obj: unstructured.Unstructured{Object: map[string]interface{}{
+				"kind": "Pod",
+			}}, Unknown: "forbidden"},
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		status := getObjStatus(test.ObjData)
+
+		if status != test.ExpectedStatus {
+			t.Errorf("Returned status of %s was incorrect, got: %q, want: %q", test.ObjData.Obj.GetKind(), status, test.ExpectedStatus)
+		}
+	}
+}
+
+func TestGetObjHealth(t *testing.T) {
+	tests := []struct {
+		Obj            map[string]interface{}
+		Unknown        string
+		ExpectedHealth string
+	}{
+		{map[string]interface{}{"kind": "Pod", "status": map[string]interface{}{"phase": "Running"}}, "", HealthHealthy},
+		{map[string]interface{}{"kind": "Pod", "status": map[string]interface{}{"phase": "Pending"}}, "", HealthProgressing},
+		{map[string]interface{}{"kind": "Pod", "status": map[string]interface{}{"phase": "Failed"}}, "", HealthUnhealthy},
+		{map[string]interface{}{"kind": "Pod", "status": map[string]interface{}{"phase": "Succeeded"}}, "", HealthHealthy},
+		{map[string]interface{}{"kind": "Pod", "status": map[string]interface{}{
+			"phase":      "Running",
+			"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False"}},
+		}}, "", HealthProgressing},
+		{map[string]interface{}{"kind": "Pod", "status": map[string]interface{}{
+			"phase":             "Running",
+			"containerStatuses": []interface{}{map[string]interface{}{"state": map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}}}},
+		}}, "", HealthUnhealthy},
+		{map[string]interface{}{"kind": "Pod", "status": map[string]interface{}{
+			"phase":             "Pending",
+			"containerStatuses": []interface{}{map[string]interface{}{"state": map[string]interface{}{"waiting": map[string]interface{}{"reason": "ImagePullBackOff"}}}},
+		}}, "", HealthUnhealthy},
+		{map[string]interface{}{"kind": "Pod"}, "", ""},
+		{map[string]interface{}{"kind": "Pod"}, "forbidden", ""},
+		{map[string]interface{}{"kind": "Deployment", "spec": map[string]interface{}{"replicas": int64(3)}, "status": map[string]interface{}{"readyReplicas": int64(3)}}, "", HealthHealthy},
+		{map[string]interface{}{"kind": "Deployment", "spec": map[string]interface{}{"replicas": int64(3)}, "status": map[string]interface{}{"readyReplicas": int64(1)}}, "", HealthProgressing},
+		{map[string]interface{}{"kind": "StatefulSet", "status": map[string]interface{}{}}, "", HealthUnhealthy},
+		{map[string]interface{}{"kind": "ReplicaSet", "spec": map[string]interface{}{"replicas": int64(0)}, "status": map[string]interface{}{}}, "", HealthHealthy},
+		{map[string]interface{}{"kind": "DaemonSet", "spec": map[string]interface{}{}}, "", ""},
+		{map[string]interface{}{"kind": "Service", "spec": map[string]interface{}{"type": "ClusterIP"}}, "", ""},
+	}
+
+	for _, test := range tests {
+		o := ObjData{Obj: unstructured.Unstructured{Object: test.Obj}, Unknown: test.Unknown}
+		health := getObjHealth(o)
+
+		if health != test.ExpectedHealth {
+			t.Errorf("Returned health of %v was incorrect, got: %q, want: %q", test.Obj, health, test.ExpectedHealth)
+		}
+	}
+}
+
+func TestGetServiceHealth(t *testing.T) {
+	pod := func(ready string) ObjData {
+		obj := map[string]interface{}{"kind": "Pod"}
+		if ready != "" {
+			obj["status"] = map[string]interface{}{
+				"phase":      "Running",
+				"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": ready}},
+			}
+		}
+
+		return ObjData{Obj: unstructured.Unstructured{Object: obj}, Hierarchy: "lower"}
+	}
+
+	tests := []struct {
+		Pods           []ObjData
+		ExpectedHealth string
+	}{
+		{[]ObjData{pod("True"), pod("True")}, HealthHealthy},
+		{[]ObjData{pod("True"), pod("False")}, HealthProgressing},
+		{[]ObjData{pod("False")}, HealthUnhealthy},
+		{[]ObjData{pod("")}, ""},
+		{[]ObjData{}, ""},
+	}
+
+	for _, test := range tests {
+		o := ObjData{
+			Obj:             unstructured.Unstructured{Object: map[string]interface{}{"kind": "Service"}},
+			RelatedObjsData: test.Pods,
+		}
+		health := getObjHealth(o)
+
+		if health != test.ExpectedHealth {
+			t.Errorf("Returned health of the service with %d pods was incorrect, got: %q, want: %q", len(test.Pods), health, test.ExpectedHealth)
+		}
+	}
+}
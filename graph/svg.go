@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Colors of the images
+const (
+	imageNodeFill      = "#e8f0fe"
+	imageNodeStroke    = "#4a6fa5"
+	imageUnknownFill   = "#f3f3f3"
+	imageUnknownStroke = "#999999"
+	imageEdgeStroke    = "#888888"
+	imageTextFill      = "#000000"
+	imageDetailsFill   = "#555555"
+)
+
+// createSVGGraph returns a string holding the SVG image of the objects data,
+// drawn with the layout used for the PNG image
+func createSVGGraph(objsData []ObjData) string {
+	l := NewLayout(NewGraph(objsData))
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", l.Width, l.Height, l.Width, l.Height)
+	fmt.Fprintf(b, "  <defs>\n    <marker id=\"arrow\" viewBox=\"0 0 10 10\" refX=\"10\" refY=\"5\" markerWidth=\"8\" markerHeight=\"8\" orient=\"auto\">\n")
+	fmt.Fprintf(b, "      <path d=\"M 0 0 L 10 5 L 0 10 z\" fill=\"%s\"/>\n    </marker>\n  </defs>\n", imageEdgeStroke)
+	fmt.Fprintln(b, "  <rect width=\"100%\" height=\"100%\" fill=\"#ffffff\"/>")
+
+	for _, e := range l.Edges {
+		fmt.Fprintf(b, "  <line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"%s\" stroke-width=\"1.5\" marker-end=\"url(#arrow)\">", e.X1, e.Y1, e.X2, e.Y2, imageEdgeStroke)
+		fmt.Fprintf(b, "<title>%s</title></line>\n", escapeXML(e.Relation))
+	}
+
+	for _, n := range l.Nodes {
+		fill, stroke, dash := imageNodeFill, imageNodeStroke, ""
+		if n.Unknown != "" {
+			fill, stroke, dash = imageUnknownFill, imageUnknownStroke, " stroke-dasharray=\"5 3\""
+		}
+
+		fmt.Fprintf(b, "  <g>\n    <title>%s</title>\n", escapeXML(n.ID))
+		fmt.Fprintf(b, "    <rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" rx=\"6\" fill=\"%s\" stroke=\"%s\" stroke-width=\"1.5\"%s/>\n", n.X, n.Y, n.Width, n.Height, fill, stroke, dash)
+
+		for i, line := range n.Lines {
+			color := imageTextFill
+			if i > 0 {
+				color = imageDetailsFill
+			}
+
+			fmt.Fprintf(b, "    <text x=\"%d\" y=\"%d\" font-family=\"monospace\" font-size=\"18\" fill=\"%s\">%s</text>\n", n.X+layoutPadding, n.Y+layoutPadding+(i+1)*layoutLineHeight-4, color, escapeXML(line))
+		}
+
+		fmt.Fprintln(b, "  </g>")
+	}
+
+	fmt.Fprintln(b, "</svg>")
+
+	return b.String()
+}
+
+// escapeXML returns the text with the XML special characters escaped
+func escapeXML(s string) string {
+	b := &strings.Builder{}
+	_ = xml.EscapeText(b, []byte(s))
+
+	return b.String()
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/klog/v2"
 )
 
@@ -29,7 +30,13 @@ func ToJSON(v interface{}) string {
 	return string(b)
 }
 
-// GetPrettyString returns a string without dashes
-func GetPrettyString(ugly string) string {
-	return strings.ReplaceAll(ugly, "-", "")
+// GetObjID returns an ID that identifies an object in the graph, e.g. deployment.apps/default/foo
+func GetObjID(obj unstructured.Unstructured) string {
+	kind := strings.ToLower(obj.GetKind())
+
+	if group := obj.GroupVersionKind().Group; group != "" {
+		kind = kind + "." + group
+	}
+
+	return kind + "/" + obj.GetNamespace() + "/" + obj.GetName()
 }
@@ -2,6 +2,8 @@ package graph
 
 import (
 	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func TestContains(t *testing.T) {
@@ -70,23 +72,41 @@ func TestToJSON(t *testing.T) {
 
 }
 
-func TestGetPrettyString(t *testing.T) {
+func TestGetObjID(t *testing.T) {
 	tests := []struct {
-		UglyString string
-		Expected   string
+		Obj      unstructured.Unstructured
+		Expected string
 	}{
 		{
-			"-Rl-Uj2-6j-X-n0g",
-			"RlUj26jXn0g",
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "apps/v1",
+					"kind":       "Deployment",
+					"metadata": map[string]interface{}{
+						"name":      "deployment-foo",
+						"namespace": "default",
+					},
+				},
+			},
+			"deployment.apps/default/deployment-foo",
 		},
 		{
-			"me-Pl-va-6V---L0S",
-			"mePlva6VL0S",
+			unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "Pod",
+					"metadata": map[string]interface{}{
+						"name":      "pod-foo",
+						"namespace": "default",
+					},
+				},
+			},
+			"pod/default/pod-foo",
 		},
 	}
 
 	for _, test := range tests {
-		r := GetPrettyString(test.UglyString)
+		r := GetObjID(test.Obj)
 
 		if r != test.Expected {
 			t.Errorf("Returned result was incorrect, got: %s want: %s", r, test.Expected)
@@ -0,0 +1,393 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// DefaultWatchInterval is the default time to wait for more events before the graph is rebuilt
+const DefaultWatchInterval = 500 * time.Millisecond
+
+// InformerSource gets the objects from the caches of the informers. Factories holds the
+// informer factory of each watched namespace, the empty namespace for all namespaces.
+// Errors holds, per namespace, the error returned for the resources that could not be
+// watched, e.g. forbidden.
+type InformerSource struct {
+	Factories map[string]dynamicinformer.DynamicSharedInformerFactory
+	Errors    map[string]map[schema.GroupResource]error
+}
+
+// NewInformerSource returns a new InformerSource struct
+func NewInformerSource(factories map[string]dynamicinformer.DynamicSharedInformerFactory) *InformerSource {
+	return &InformerSource{
+		Factories: factories,
+		Errors:    map[string]map[schema.GroupResource]error{},
+	}
+}
+
+// Get returns an object from the cache. The objects of the namespaces
+// that are not watched are not found.
+func (s *InformerSource) Get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	factory, err := s.getFactory(gvr, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if factory == nil {
+		return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+	}
+
+	o, err := factory.ForResource(gvr).Lister().ByNamespace(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return toCachedUnstructured(o, gvr)
+}
+
+// List returns a list of objects from the cache that match the list options.
+// All namespaces are the objects of all the watched namespaces.
+func (s *InformerSource) List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	namespaces := []string{namespace}
+
+	if _, found := s.Factories[namespace]; namespace == metav1.NamespaceAll && !found {
+		namespaces = []string{}
+		for ns := range s.Factories {
+			namespaces = append(namespaces, ns)
+		}
+		sort.Strings(namespaces)
+	}
+
+	objs := []unstructured.Unstructured{}
+
+	for _, ns := range namespaces {
+		factory, err := s.getFactory(gvr, ns)
+		if err != nil {
+			return nil, err
+		}
+
+		if factory == nil {
+			continue
+		}
+
+		var l []runtime.Object
+
+		if ns == metav1.NamespaceAll {
+			l, err = factory.ForResource(gvr).Lister().List(labels.Everything())
+		} else {
+			l, err = factory.ForResource(gvr).Lister().ByNamespace(ns).List(labels.Everything())
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, o := range l {
+			u, err := toCachedUnstructured(o, gvr)
+			if err != nil {
+				return nil, err
+			}
+
+			objs = append(objs, *u)
+		}
+	}
+
+	return NewObjectSource(objs).List(ctx, gvr, namespace, opts)
+}
+
+// getFactory returns the informer factory that watches the resource in the namespace,
+// or nil if the namespace is not watched. The error of the resource is returned if
+// it could not be watched.
+func (s *InformerSource) getFactory(gvr schema.GroupVersionResource, namespace string) (dynamicinformer.DynamicSharedInformerFactory, error) {
+	for _, ns := range []string{namespace, metav1.NamespaceAll} {
+		if err, ok := s.Errors[ns][gvr.GroupResource()]; ok {
+			return nil, err
+		}
+
+		if factory, ok := s.Factories[ns]; ok {
+			return factory, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// toCachedUnstructured returns a copy of an object of the cache
+func toCachedUnstructured(o runtime.Object, gvr schema.GroupVersionResource) (*unstructured.Unstructured, error) {
+	u, ok := o.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("object of '%s' can not be read", gvr.GroupResource())
+	}
+
+	return u.DeepCopy(), nil
+}
+
+// Watcher holds the information to watch the objects and print the graph when they change
+type Watcher struct {
+	Client        dynamic.Interface
+	Namespaces    []string
+	Roots         []Root
+	LabelSelector string
+	Out           io.Writer
+	Output        string
+	// Color adds the health indicators to the tree and wide outputs when the graph is redrawn
+	Color bool
+	// Redraw clears the terminal and prints the whole graph on each change,
+	// otherwise the added, removed and changed nodes and edges are printed,
+	// as JSON records, one per line, when the output is jsonl
+	Redraw   bool
+	Interval time.Duration
+	snapshot *Snapshot
+}
+
+// WatchEvent holds a node or edge that was added, updated or removed in the graph
+type WatchEvent struct {
+	Time string    `json:"time"`
+	Type string    `json:"type"`
+	Node *DiffNode `json:"node,omitempty"`
+	Edge *DiffEdge `json:"edge,omitempty"`
+}
+
+// NewWatcher returns a new Watcher struct
+func NewWatcher(client dynamic.Interface, out io.Writer, output string, namespaces []string, roots []Root) *Watcher {
+	return &Watcher{
+		Client:     client,
+		Out:        out,
+		Output:     output,
+		Namespaces: namespaces,
+		Roots:      roots,
+		Interval:   DefaultWatchInterval,
+		snapshot:   NewSnapshot([]ObjData{}, nil),
+	}
+}
+
+// Watch starts the informers of the supported kinds, one factory per namespace, and prints
+// the graph each time the objects change, until the context is done. The related kinds of
+// any kind of root cover all the supported kinds, so all of them are watched. The graph is
+// built again from the caches of the informers once per interval, without API calls.
+func (w *Watcher) Watch(ctx context.Context) error {
+	klog.V(1).Infoln("start the informers to watch the objects")
+
+	namespaces := w.Namespaces
+	if Contains(metav1.NamespaceAll, namespaces) {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	factories := map[string]dynamicinformer.DynamicSharedInformerFactory{}
+	for _, ns := range namespaces {
+		factories[ns] = dynamicinformer.NewFilteredDynamicSharedInformerFactory(w.Client, 0, ns, nil)
+	}
+
+	s := NewInformerSource(factories)
+
+	// changed is notified on every event, the graph is rebuilt once per interval
+	changed := make(chan struct{}, 1)
+	notify := func(interface{}) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	for _, k := range supportedKinds {
+		gvr, err := getGroupVersionResource(k)
+		if err != nil {
+			return err
+		}
+
+		for _, ns := range namespaces {
+			// The informers of the resources that can not be listed would never sync
+			_, err = w.Client.Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{Limit: 1})
+			if err != nil {
+				if getUnknownReason(err) == "" {
+					return err
+				}
+
+				klog.V(1).Infof("'%s' in namespace '%s' can not be watched: %s", gvr.GroupResource(), ns, err)
+				if s.Errors[ns] == nil {
+					s.Errors[ns] = map[schema.GroupResource]error{}
+				}
+				s.Errors[ns][gvr.GroupResource()] = err
+				continue
+			}
+
+			factories[ns].ForResource(gvr).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+				AddFunc:    notify,
+				UpdateFunc: func(_, o interface{}) { notify(o) },
+				DeleteFunc: notify,
+			})
+		}
+	}
+
+	for _, ns := range namespaces {
+		factories[ns].Start(ctx.Done())
+	}
+
+	for _, ns := range namespaces {
+		for gvr, synced := range factories[ns].WaitForCacheSync(ctx.Done()) {
+			if !synced {
+				if ctx.Err() != nil {
+					return nil
+				}
+
+				return fmt.Errorf("cache of '%s' in namespace '%s' could not be synced", gvr.GroupResource(), ns)
+			}
+		}
+	}
+
+	for {
+		err := w.print(s)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+
+		// Wait for the rest of the events of a change, e.g. a rolling update
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(w.Interval):
+		}
+
+		select {
+		case <-changed:
+		default:
+		}
+	}
+}
+
+// print builds the graph from the source and prints it, or prints its changes
+func (w *Watcher) print(s Source) error {
+	b := NewBuilder(s, io.Discard, w.Output, w.Namespaces, w.Roots)
+	b.LabelSelector = w.LabelSelector
+	b.ChunkSize = 0
+
+	err := b.Collect()
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+
+		// The main object may be deleted and created again while it is watched
+		klog.V(1).Infof("main object not found: %s", err)
+		b.ObjsData = []ObjData{}
+	}
+
+	snapshot := NewSnapshot(b.ObjsData, b.Warnings)
+	defer func() { w.snapshot = snapshot }()
+
+	if w.Redraw {
+		fmt.Fprint(w.Out, "\033[H\033[2J")
+		fmt.Fprintf(w.Out, "Last change at %s, press Ctrl+C to exit\n", time.Now().Format(time.RFC3339))
+
+		p := NewPrinter(b.ObjsData, w.Output, w.Out)
+		p.Color = w.Color
+
+		return p.Print()
+	}
+
+	// A change of the status of an object, e.g. a restart, is printed as an update
+	d := newDiff(w.snapshot, snapshot, false, true)
+
+	if w.Output == OutputJSONLines {
+		return w.printJSONEvents(d)
+	}
+
+	w.printEvents(d)
+
+	return nil
+}
+
+// printEvents prints a line for each node and edge that was added, removed or changed
+func (w *Watcher) printEvents(d *Diff) {
+	for _, n := range d.Nodes {
+		if n.Status != DiffUnchanged {
+			fmt.Fprintf(w.Out, "%-9s node %s\n", n.Status, getDiffNodeName(n))
+		}
+	}
+
+	for _, e := range d.Edges {
+		if e.Status != DiffUnchanged {
+			fmt.Fprintf(w.Out, "%-9s edge %s -> %s\n", e.Status, getDiffNodeName(*d.getNode(e.From)), getDiffNodeName(*d.getNode(e.To)))
+		}
+	}
+}
+
+// printJSONEvents prints a JSON record for each node and edge that was added, updated or removed
+func (w *Watcher) printJSONEvents(d *Diff) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	events := []WatchEvent{}
+
+	for _, n := range d.Nodes {
+		if n.Status == DiffUnchanged {
+			continue
+		}
+
+		n := n
+		e := WatchEvent{Time: now, Type: getWatchEventType(n.Status), Node: &n}
+		e.Node.Status = ""
+
+		events = append(events, e)
+	}
+
+	for _, edge := range d.Edges {
+		if edge.Status == DiffUnchanged {
+			continue
+		}
+
+		edge := edge
+		e := WatchEvent{Time: now, Type: getWatchEventType(edge.Status), Edge: &edge}
+		e.Edge.Status = ""
+
+		events = append(events, e)
+	}
+
+	for _, e := range events {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(w.Out, string(b))
+	}
+
+	return nil
+}
+
+// getWatchEventType returns the event type of a diff status
+func getWatchEventType(status string) string {
+	switch status {
+	case DiffChanged:
+		return "updated"
+	}
+
+	return status
+}
+
+// getDiffNodeName returns the kind, namespace and name of the node, e.g. [Pod] default/foo
+func getDiffNodeName(n DiffNode) string {
+	if n.Namespace == "" {
+		return fmt.Sprintf("[%s] %s", n.Kind, n.Name)
+	}
+
+	return fmt.Sprintf("[%s] %s/%s", n.Kind, n.Namespace, n.Name)
+}
@@ -0,0 +1,237 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// syncBuffer is a buffer that can be written by the watcher while the test reads it
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// waitForOutput waits until the output holds the lines
+func waitForOutput(t *testing.T, o *syncBuffer, lines ...string) {
+	for i := 0; i < 100; i++ {
+		found := true
+		for _, l := range lines {
+			found = found && strings.Contains(o.String(), l+"\n")
+		}
+
+		if found {
+			return
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatalf("Returned output was incorrect,\ngot:\n%s\nwant lines:\n%s", o.String(), strings.Join(lines, "\n"))
+}
+
+// newFakeDynamicClient returns a dynamic client with the mock objects where ingresses are forbidden
+func newFakeDynamicClient() *fake.FakeDynamicClient {
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, k := range supportedKinds {
+		gvr, _ := getGroupVersionResource(k)
+		listKinds[gvr] = getKindName(k) + "List"
+	}
+
+	objs := []runtime.Object{}
+	for _, o := range NewFakeSource("default").Objs {
+		objs = append(objs, o.DeepCopy())
+	}
+
+	c := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objs...)
+	c.PrependReactor("list", "ingresses", func(a clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(a.GetResource().GroupResource(), "", fmt.Errorf("access denied"))
+	})
+
+	return c
+}
+
+func TestWatch(t *testing.T) {
+	c := newFakeDynamicClient()
+	o := &syncBuffer{}
+
+	w := NewWatcher(c, o, OutputTree, []string{"default"}, []Root{{Kind: "service", Name: "service-foo"}})
+	w.Interval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+
+	go func() {
+		errs <- w.Watch(ctx)
+	}()
+
+	waitForOutput(t, o,
+		"added     node [Service] default/service-foo",
+		"added     node [Ingress] default/unknown (forbidden)",
+		"added     edge [Service] default/service-foo -> [Pod] default/pod-foo-1",
+	)
+
+	pod := newTestObj("v1", "Pod", "default", "pod-foo-3", map[string]string{"app": "foo", "version": "v1"})
+
+	_, err := c.Resource(schema.GroupVersionResource{Version: "v1", Resource: "pods"}).Namespace("default").Create(ctx, &pod, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForOutput(t, o,
+		"added     node [Pod] default/pod-foo-3",
+		"added     edge [Service] default/service-foo -> [Pod] default/pod-foo-3",
+	)
+
+	// Only the status of the pod changes
+	pod.Object["status"] = map[string]interface{}{"phase": "Running"}
+
+	_, err = c.Resource(schema.GroupVersionResource{Version: "v1", Resource: "pods"}).Namespace("default").Update(ctx, &pod, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForOutput(t, o, "changed   node [Pod] default/pod-foo-3")
+
+	cancel()
+
+	err = <-errs
+	if err != nil {
+		t.Errorf("Watch returned an error. Error: %q", err)
+	}
+}
+
+func TestWatchJSONLines(t *testing.T) {
+	o := &syncBuffer{}
+
+	w := NewWatcher(newFakeDynamicClient(), o, OutputJSONLines, []string{"default"}, []Root{{Kind: "service", Name: "service-foo"}})
+	w.Interval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+
+	go func() {
+		errs <- w.Watch(ctx)
+	}()
+
+	waitForOutput(t, o, `"edge":{"from":"statefulset.apps/default/statefulset-foo-1","to":"pod/default/pod-foo-1","relation":"ownerReference"}}`)
+
+	cancel()
+
+	err := <-errs
+	if err != nil {
+		t.Errorf("Watch returned an error. Error: %q", err)
+	}
+
+	for _, l := range strings.Split(strings.TrimSpace(o.String()), "\n") {
+		e := WatchEvent{}
+
+		err := json.Unmarshal([]byte(l), &e)
+		if err != nil {
+			t.Errorf("Returned line is not a JSON record: %s", l)
+			continue
+		}
+
+		if _, err := time.Parse(time.RFC3339, e.Time); err != nil || e.Type != DiffAdded || (e.Node == nil) == (e.Edge == nil) {
+			t.Errorf("Returned record was incorrect, got: %s", l)
+		}
+	}
+}
+
+func TestWatchNamespaces(t *testing.T) {
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, k := range supportedKinds {
+		gvr, _ := getGroupVersionResource(k)
+		listKinds[gvr] = getKindName(k) + "List"
+	}
+
+	objs := []runtime.Object{}
+	for _, ns := range []string{"foo", "bar", "baz"} {
+		service := newTestObj("v1", "Service", ns, "web", nil)
+		service.Object["spec"] = map[string]interface{}{"selector": map[string]interface{}{"app": "web"}}
+		pod := newTestObj("v1", "Pod", ns, "web-"+ns, map[string]string{"app": "web"})
+
+		objs = append(objs, &service, &pod)
+	}
+
+	c := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objs...)
+	o := &syncBuffer{}
+
+	w := NewWatcher(c, o, OutputTree, []string{"foo", "bar"}, []Root{{Kind: "service", Name: "web"}})
+	w.Interval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+
+	go func() {
+		errs <- w.Watch(ctx)
+	}()
+
+	waitForOutput(t, o,
+		"added     edge [Service] foo/web -> [Pod] foo/web-foo",
+		"added     edge [Service] bar/web -> [Pod] bar/web-bar",
+	)
+
+	cancel()
+
+	err := <-errs
+	if err != nil {
+		t.Errorf("Watch returned an error. Error: %q", err)
+	}
+
+	if strings.Contains(o.String(), "baz") {
+		t.Errorf("Returned output holds the objects of a namespace that is not watched,\ngot:\n%s", o.String())
+	}
+
+	// Each namespace is watched by its own informers instead of all namespaces
+	for _, a := range c.Actions() {
+		if a.GetVerb() == "list" && !Contains(a.GetNamespace(), []string{"foo", "bar"}) {
+			t.Errorf("Returned list namespace was incorrect for '%s', got: %q want: foo or bar", a.GetResource().Resource, a.GetNamespace())
+		}
+	}
+}
+
+func TestInformerSourceErrors(t *testing.T) {
+	s := NewInformerSource(nil)
+
+	gvr, _ := getGroupVersionResource("ingress")
+	s.Errors["default"] = map[schema.GroupResource]error{
+		gvr.GroupResource(): apierrors.NewForbidden(gvr.GroupResource(), "", fmt.Errorf("access denied")),
+	}
+
+	_, err := s.List(context.TODO(), gvr, "default", metav1.ListOptions{})
+	if !apierrors.IsForbidden(err) {
+		t.Errorf("Returned error was incorrect, got: %v want: forbidden", err)
+	}
+
+	_, err = s.Get(context.TODO(), gvr, "default", "ingress-foo")
+	if !apierrors.IsForbidden(err) {
+		t.Errorf("Returned error was incorrect, got: %v want: forbidden", err)
+	}
+}
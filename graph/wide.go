@@ -0,0 +1,221 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// wideTabWidth is the number of columns of the tabs indenting the tree
+const wideTabWidth = 8
+
+// wideColumns holds the headers of the columns of the wide tree
+var wideColumns = []string{"NAME", "READY", "STATUS", "RESTARTS", "AGE", "NODE", "TYPE", "PORTS", "HOSTS"}
+
+// createWideTreeGraph returns a string holding the tree graphs of the namespaces
+// with the status columns of each object aligned after the tree, like kubectl get -o wide.
+// The columns are aligned across all the namespaces under a single header.
+func createWideTreeGraph(namespaces []string, groups map[string][]ObjData, expanded map[string]bool, now time.Time, color bool) string {
+	trees := map[string][][]TreeLine{}
+	widths := make([]int, len(wideColumns))
+
+	updateWidths := func(row []string) {
+		for i, cell := range row {
+			if w := getTextWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	updateWidths(wideColumns)
+
+	for _, ns := range namespaces {
+		for _, o := range groups[ns] {
+			lines := createTreeLines(o, "", expanded)
+			for _, l := range lines {
+				updateWidths(getWideRow(l, now, color))
+			}
+
+			trees[ns] = append(trees[ns], lines)
+		}
+	}
+
+	format := func(row []string) string {
+		line := ""
+		for i, cell := range row {
+			line = line + cell
+			if i < len(row)-1 {
+				line = line + strings.Repeat(" ", widths[i]-getTextWidth(cell)+3)
+			}
+		}
+
+		return strings.TrimRight(line, " ")
+	}
+
+	g := "\n" + format(wideColumns) + "\n"
+
+	for _, ns := range namespaces {
+		if len(namespaces) > 1 {
+			g = g + fmt.Sprintf("\nNamespace: %s\n", ns)
+		}
+
+		for _, lines := range trees[ns] {
+			rows := []string{}
+			for _, l := range lines {
+				rows = append(rows, format(getWideRow(l, now, color)))
+			}
+
+			g = g + fmt.Sprintf("\n%s\n\n", strings.Join(rows, "\n"))
+		}
+	}
+
+	return g
+}
+
+// getWideRow returns the line of the tree followed by the status columns of its object
+func getWideRow(l TreeLine, now time.Time, color bool) []string {
+	return append([]string{getTreeLineText(l, color)}, getWideColumns(l.ObjData, now)...)
+}
+
+// getWideColumns returns the values of the status columns of the object, empty
+// when they do not apply to its kind: the ready containers or replicas, the pod
+// phase and restarts, the age, the node, the service type and ports, and the ingress hosts.
+func getWideColumns(o ObjData, now time.Time) []string {
+	var ready, status, restarts, age, node, serviceType, ports, hosts string
+
+	if o.Unknown != "" {
+		return []string{ready, status, restarts, age, node, serviceType, ports, hosts}
+	}
+
+	obj := o.Obj
+
+	if t := obj.GetCreationTimestamp(); !t.IsZero() {
+		age = duration.HumanDuration(now.Sub(t.Time))
+	}
+
+	if r, d, ok := getReadyReplicas(obj); ok {
+		ready = fmt.Sprintf("%d/%d", r, d)
+	}
+
+	if obj.GetDeletionTimestamp() != nil {
+		status = "Terminating"
+	}
+
+	switch strings.ToLower(obj.GetKind()) {
+	case "pod":
+		if _, found := obj.Object["status"]; found {
+			ready, restarts = getPodContainers(obj)
+		}
+
+		if status == "" {
+			status, _, _ = unstructured.NestedString(obj.Object, "status", "phase")
+		}
+
+		node, _, _ = unstructured.NestedString(obj.Object, "spec", "nodeName")
+	case "service":
+		serviceType, _, _ = unstructured.NestedString(obj.Object, "spec", "type")
+		ports = getServicePorts(obj)
+	case "ingress":
+		hosts = getIngressHosts(obj)
+	}
+
+	return []string{ready, status, restarts, age, node, serviceType, ports, hosts}
+}
+
+// getPodContainers returns the ready containers of the pod, e.g. 1/2, and the
+// sum of the restarts of its containers
+func getPodContainers(obj unstructured.Unstructured) (string, string) {
+	containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "containers")
+	containerStatuses, _, _ := unstructured.NestedSlice(obj.Object, "status", "containerStatuses")
+
+	ready, restarts := 0, int64(0)
+	for _, c := range containerStatuses {
+		cs, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if r, _, _ := unstructured.NestedBool(cs, "ready"); r {
+			ready++
+		}
+
+		n, _, _ := unstructured.NestedInt64(cs, "restartCount")
+		restarts = restarts + n
+	}
+
+	return fmt.Sprintf("%d/%d", ready, len(containers)), fmt.Sprint(restarts)
+}
+
+// getServicePorts returns the ports of the service as kubectl prints them, e.g. 80:30080/TCP,443/TCP
+func getServicePorts(obj unstructured.Unstructured) string {
+	ports, _, _ := unstructured.NestedSlice(obj.Object, "spec", "ports")
+
+	values := []string{}
+	for _, p := range ports {
+		port, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		number, _, _ := unstructured.NestedInt64(port, "port")
+		nodePort, _, _ := unstructured.NestedInt64(port, "nodePort")
+		protocol, _, _ := unstructured.NestedString(port, "protocol")
+		if protocol == "" {
+			protocol = "TCP"
+		}
+
+		if nodePort > 0 {
+			values = append(values, fmt.Sprintf("%d:%d/%s", number, nodePort, protocol))
+		} else {
+			values = append(values, fmt.Sprintf("%d/%s", number, protocol))
+		}
+	}
+
+	return strings.Join(values, ",")
+}
+
+// getIngressHosts returns the hosts of the ingress rules, * for the rules without host
+func getIngressHosts(obj unstructured.Unstructured) string {
+	rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", "rules")
+
+	hosts := []string{}
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		host, _, _ := unstructured.NestedString(rule, "host")
+		if host == "" {
+			host = "*"
+		}
+
+		if !Contains(host, hosts) {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return strings.Join(hosts, ",")
+}
+
+// getTextWidth returns the number of columns of the text in a terminal, the tabs
+// are expanded to the next multiple of wideTabWidth and the ANSI colors are skipped
+func getTextWidth(s string) int {
+	w, escape := 0, false
+	for _, r := range s {
+		if escape {
+			escape = r != 'm'
+		} else if r == '\033' {
+			escape = true
+		} else if r == '\t' {
+			w = w + wideTabWidth - w%wideTabWidth
+		} else {
+			w++
+		}
+	}
+
+	return w
+}
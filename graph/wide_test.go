@@ -0,0 +1,120 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCreateWideTreeGraph(t *testing.T) {
+	now := time.Date(2021, 8, 2, 10, 0, 0, 0, time.UTC)
+
+	objData := ObjData{
+		Obj: unstructured.Unstructured{Object: map[string]interface{}{
+			"kind": "Service",
+			"metadata": map[string]interface{}{
+				"name":              "service-foo",
+				"namespace":         "default",
+				"creationTimestamp": "2021-07-30T10:00:00Z",
+			},
+			"spec": map[string]interface{}{
+				"type": "NodePort",
+				"ports": []interface{}{
+					map[string]interface{}{"port": int64(80), "nodePort": int64(30080), "protocol": "TCP"},
+					map[string]interface{}{"port": int64(53), "protocol": "UDP"},
+				},
+			},
+		}},
+		RelatedObjsData: []ObjData{
+			{
+				Obj: unstructured.Unstructured{Object: map[string]interface{}{
+					"kind":     "Ingress",
+					"metadata": map[string]interface{}{"name": "ingress-foo", "namespace": "default"},
+					"spec": map[string]interface{}{"rules": []interface{}{
+						map[string]interface{}{"host": "foo.example.com"},
+						map[string]interface{}{},
+					}},
+				}},
+				Hierarchy:       "upper",
+				RelatedObjsData: []ObjData{},
+			},
+			{
+				Obj: unstructured.Unstructured{Object: map[string]interface{}{
+					"kind": "Pod",
+					"metadata": map[string]interface{}{
+						"name":              "pod-foo-1",
+						"namespace":         "default",
+						"creationTimestamp": "2021-08-02T09:55:00Z",
+					},
+					"spec": map[string]interface{}{
+						"nodeName":   "node-1",
+						"containers": []interface{}{map[string]interface{}{"name": "app"}, map[string]interface{}{"name": "proxy"}},
+					},
+					"status": map[string]interface{}{
+						"phase": "Running",
+						"containerStatuses": []interface{}{
+							map[string]interface{}{"ready": true, "restartCount": int64(2)},
+							map[string]interface{}{"ready": false, "restartCount": int64(1)},
+						},
+					},
+				}},
+				Hierarchy: "lower",
+				RelatedObjsData: []ObjData{
+					{
+						Obj: unstructured.Unstructured{Object: map[string]interface{}{
+							"kind":     "StatefulSet",
+							"metadata": map[string]interface{}{"name": "statefulset-foo", "namespace": "default"},
+							"spec":     map[string]interface{}{"replicas": int64(3)},
+							"status":   map[string]interface{}{"readyReplicas": int64(2)},
+						}},
+						Hierarchy:       "upper",
+						RelatedObjsData: []ObjData{},
+					},
+				},
+			},
+			{
+				Obj:             unstructured.Unstructured{Object: map[string]interface{}{"kind": "Ingress"}},
+				Hierarchy:       "upper",
+				Unknown:         "forbidden",
+				RelatedObjsData: []ObjData{},
+			},
+		},
+	}
+
+	namespaces, groups := groupByNamespace([]ObjData{objData})
+
+	expected := "\n" +
+		"NAME                                                READY   STATUS    RESTARTS   AGE   NODE     TYPE       PORTS                 HOSTS\n" +
+		"\n" +
+		"\t┌── [Ingress] unknown (forbidden)\n" +
+		"\t┌── [Ingress] ingress-foo                                                                                                foo.example.com,*\n" +
+		"[Service] service-foo                                                            3d             NodePort   80:30080/TCP,53/UDP\n" +
+		"\t\t┌── [StatefulSet] statefulset-foo   2/3\n" +
+		"\t└── [Pod] pod-foo-1                         1/2     Running   3          5m    node-1\n" +
+		"\n"
+
+	result := createWideTreeGraph(namespaces, groups, getExpandedIDs([]ObjData{objData}), now, false)
+	if result != expected {
+		t.Errorf("Returned graph was incorrect,\ngot:\n%s\nwant:\n%s", result, expected)
+	}
+}
+
+func TestGetTextWidth(t *testing.T) {
+	tests := []struct {
+		Text          string
+		ExpectedWidth int
+	}{
+		{"[Pod] foo", 9},
+		{"\t└── [Pod] foo", 21},
+		{"\t\t┌── [Pod] foo", 29},
+		{"ab\tc", 9},
+		{"\t└── \033[32m✔ [Pod] foo\033[0m", 23},
+	}
+
+	for _, test := range tests {
+		if w := getTextWidth(test.Text); w != test.ExpectedWidth {
+			t.Errorf("Returned width of %q was incorrect, got: %d, want: %d", test.Text, w, test.ExpectedWidth)
+		}
+	}
+}